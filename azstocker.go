@@ -12,7 +12,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dustin/go-humanize"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 	googleHTTP "google.golang.org/api/transport/http"
@@ -21,15 +25,19 @@ import (
 // override for setting time in tests
 var getNow = time.Now
 
-const (
-	springSummerStockingSheetID   = "1S5wsDfGzEInV64UKjUPzexAe2KOO1KocfB4dJH7oVrs"
-	springSummerStockingSheetName = "%d Spring/Summer"
+// Now is the current time in Arizona. This is the clock used to choose the current Season and compare
+// stocking dates, so callers of CurrentSeason should use it
+func Now() time.Time {
+	return getNow().In(azTime)
+}
 
-	winterStockingSheetID   = "1PZuTV-zi5vMdxaMSnGx6c-QxeQQm-6DRQJJPKAZDjZM"
-	winterStockingSheetName = "%d-%d"
+var tracer = otel.Tracer("github.com/calvinmclean/azstocker")
+
+const (
+	springSummerStockingSheetID = "1S5wsDfGzEInV64UKjUPzexAe2KOO1KocfB4dJH7oVrs"
+	winterStockingSheetID       = "1PZuTV-zi5vMdxaMSnGx6c-QxeQQm-6DRQJJPKAZDjZM"
 
-	cfpStockingSheetID   = "1xJYPRrX2Gb7ACr6HxPB7mlsCw9K8NvClLfBIw7qjTcA"
-	cfpStockingSheetName = "CFP Stocking Calendar Schedule"
+	cfpStockingSheetID = "1xJYPRrX2Gb7ACr6HxPB7mlsCw9K8NvClLfBIw7qjTcA"
 )
 
 const (
@@ -38,6 +46,9 @@ const (
 	SpringSummerProgram Program = "springsummer"
 )
 
+// Programs are all of the stocking Programs
+var Programs = []Program{CFProgram, WinterProgram, SpringSummerProgram}
+
 const (
 	Catfish     Fish = "Catfish"
 	Trout       Fish = "Trout"
@@ -45,11 +56,49 @@ const (
 	NoneFish    Fish = "None"
 )
 
-var azTime = time.FixedZone("AZ", -7*3600)
+// Language is used to localize human-readable output like month names and relative times
+type Language string
+
+const (
+	English = Language(i18n.English)
+	Spanish = Language(i18n.Spanish)
+)
+
+// ParseLanguage parses a language code like "es" or "es-MX" to a supported Language
+func ParseLanguage(code string) (Language, error) {
+	lang, ok := i18n.Parse(code)
+	if !ok {
+		return "", errors.New("unsupported language")
+	}
+	return Language(lang), nil
+}
+
+// t returns the Language's message for the key formatted with args
+func (l Language) t(key string, args ...any) string {
+	return i18n.Language(l).T(key, args...)
+}
+
+// month returns the localized name of the month
+func (l Language) month(m time.Month) string {
+	return i18n.Language(l).Month(m)
+}
+
+// relTime describes t relative to now in the Language
+func (l Language) relTime(t, now time.Time) string {
+	return i18n.Language(l).RelTime(t, now)
+}
 
 // Fish is the type of fish that is stocked
 type Fish string
 
+// NameIn returns the localized name of the Fish
+func (f Fish) NameIn(lang Language) string {
+	if f == "" {
+		return ""
+	}
+	return lang.t("fish." + string(f))
+}
+
 // ParseFish parses a string to a Fish type
 func ParseFish(f string) Fish {
 	switch strings.ToLower(f) {
@@ -78,16 +127,22 @@ func ParseProgram(p string) (Program, error) {
 	case string(SpringSummerProgram), "spring", "summer":
 		return SpringSummerProgram, nil
 	default:
-		return "", errors.New("unknown program")
+		return "", fmt.Errorf("%w: %q", ErrUnknownProgram, p)
 	}
 }
 
-// Week represents a date on the calendar and shows stocking data for that week range
+// Week represents a date range on the calendar and shows stocking data for that week range. Year, Month,
+// and Day are the first day of the range and EndMonth and EndDay are the last day, which can be in the
+// next month
 type Week struct {
-	Month time.Month
-	Day   int
-	Year  int
-	Stock Fish
+	Month    time.Month
+	Day      int
+	Year     int
+	EndMonth time.Month
+	EndDay   int
+	Stock    Fish
+	// Status is set by StockingData.Reconcile
+	Status Status
 }
 
 // Time creates a time.Time from the Year, Month, and Date of stocking
@@ -95,22 +150,100 @@ func (s Week) Time() time.Time {
 	return time.Date(s.Year, s.Month, s.Day, 0, 0, 0, 0, azTime)
 }
 
+// End is the last day of the Week's range. If the end is not set, the range is 7 days
+func (s Week) End() time.Time {
+	if s.EndDay == 0 {
+		return s.Time().AddDate(0, 0, 6)
+	}
+
+	year := s.Year
+	if s.EndMonth < s.Month {
+		year++
+	}
+	return time.Date(year, s.EndMonth, s.EndDay, 0, 0, 0, 0, azTime)
+}
+
+// Contains checks if the date in Arizona is in the Week's range
+func (s Week) Contains(t time.Time) bool {
+	return s.ContainsIn(t, Arizona)
+}
+
+// ContainsIn checks if the date in the Location is in the Week's range. Use Calendar.Location for the
+// water's time zone
+func (s Week) ContainsIn(t time.Time, loc *time.Location) bool {
+	date := dateIn(t, loc)
+	return !date.Before(s.Time()) && !date.After(s.End())
+}
+
+// setEnd sets the last day of the Week's range
+func (s *Week) setEnd(end time.Time) {
+	s.EndMonth = end.Month()
+	s.EndDay = end.Day()
+}
+
+// HumanTime describes the stocking date relative to now in Arizona, like "3 days ago", "today" if it is
+// scheduled for today, or "this week" if the Week's range includes today
 func (s Week) HumanTime() string {
-	return humanize.RelTime(s.Time(), getNow(), "ago", "from now")
+	return s.HumanTimeIn(English)
+}
+
+// HumanTimeIn is HumanTime in the specified Language. Use Calendar.HumanTimeIn for the water's time zone
+func (s Week) HumanTimeIn(lang Language) string {
+	return s.humanTimeIn(lang, Arizona)
+}
+
+func (s Week) humanTimeIn(lang Language, loc *time.Location) string {
+	now := getNow()
+	switch {
+	case dateIn(now, loc).Equal(s.Time()):
+		return lang.t("week.today")
+	case s.ContainsIn(now, loc):
+		return lang.t("week.thisWeek")
+	default:
+		return lang.relTime(s.Time(), now)
+	}
+}
+
+// DateIn formats the Week's range in the specified Language, like "2024 July 7-11" or
+// "2024 September 30 - October 4"
+func (s Week) DateIn(lang Language) string {
+	start := fmt.Sprintf("%d %s %d", s.Year, lang.month(s.Month), s.Day)
+	end := s.End()
+	switch {
+	case s.EndDay == 0 || end.Equal(s.Time()):
+		return start
+	case end.Year() != s.Year:
+		return fmt.Sprintf("%s - %d %s %d", start, end.Year(), lang.month(end.Month()), end.Day())
+	case end.Month() != s.Month:
+		return fmt.Sprintf("%s - %s %d", start, lang.month(end.Month()), end.Day())
+	default:
+		return fmt.Sprintf("%s-%d", start, end.Day())
+	}
 }
 
 // String formats the Week to show the date and stocking data
 func (s Week) String() string {
+	return s.StringIn(English)
+}
+
+// StringIn is String in the specified Language
+func (s Week) StringIn(lang Language) string {
 	if s.Year == 0 && s.Day == 0 {
-		return "No Data"
+		return lang.t("week.noData")
+	}
+	if s.Status != "" {
+		return fmt.Sprintf("%s: %q (%s)", s.DateIn(lang), s.Stock.NameIn(lang), lang.t("status."+string(s.Status)))
 	}
-	return fmt.Sprintf("%d %s %d: %q", s.Year, s.Month.String(), s.Day, s.Stock)
+	return fmt.Sprintf("%s: %q", s.DateIn(lang), s.Stock.NameIn(lang))
 }
 
 // Calendar is and ordered list of Weeks and shows all available stocking data for a specific water
 type Calendar struct {
 	WaterName string
-	Data      []Week
+	// Region is the area of the state from the sheet's section headers. It is empty if the water is
+	// not in a section
+	Region string
+	Data   []Week
 }
 
 // String formats the Calendar and excludes non-stocked dates
@@ -120,12 +253,17 @@ func (s Calendar) String() string {
 
 // Format all dates in the Calendar. If hideEmpty is set, it will exclude non-stocking days
 func (s Calendar) Format(hideEmpty bool) string {
+	return s.FormatIn(English, hideEmpty)
+}
+
+// FormatIn is Format in the specified Language
+func (s Calendar) FormatIn(lang Language, hideEmpty bool) string {
 	var sb strings.Builder
 	for _, data := range s.Data {
 		if hideEmpty && data.Stock == NoneFish {
 			continue
 		}
-		sb.WriteString(data.String())
+		sb.WriteString(data.StringIn(lang))
 		sb.WriteString("\n")
 	}
 	return strings.TrimSuffix(sb.String(), "\n")
@@ -133,44 +271,57 @@ func (s Calendar) Format(hideEmpty bool) string {
 
 // DetailFormat creates string with detailed explanation of the Calendar and accepts a few boolean controls
 func (s Calendar) DetailFormat(showAll, showAllStock, next, last bool) string {
+	return s.DetailFormatIn(English, showAll, showAllStock, next, last)
+}
+
+// DetailFormatIn is DetailFormat in the specified Language
+func (s Calendar) DetailFormatIn(lang Language, showAll, showAllStock, next, last bool) string {
 	var sb strings.Builder
 
 	// if all are unset, default to just printing scheduled times
 	if !showAll && !showAllStock && !next && !last {
-		sb.WriteString(s.Format(false))
+		sb.WriteString(s.FormatIn(lang, false))
 		return sb.String()
 	}
 
 	if showAll {
-		sb.WriteString(s.Format(false))
+		sb.WriteString(s.FormatIn(lang, false))
 		sb.WriteString("\n")
 	} else if showAllStock {
-		sb.WriteString(s.Format(true))
+		sb.WriteString(s.FormatIn(lang, true))
 		sb.WriteString("\n")
 	}
 
+	today := s.Today()
+	if (next || last) && today.Year != 0 {
+		sb.WriteString(lang.t("detail.today"))
+		sb.WriteString(today.StringIn(lang))
+		sb.WriteString("\n")
+	}
 	if last {
-		sb.WriteString("Last: ")
-		sb.WriteString(s.Last().String())
+		sb.WriteString(lang.t("detail.last"))
+		sb.WriteString(s.Last().StringIn(lang))
 		sb.WriteString("\n")
 	}
-	if next {
-		sb.WriteString("Next:")
-		sb.WriteString(s.Next().String())
+	// the next stocking is already shown if it is today
+	if next && (today.Year == 0 || s.Next() != today) {
+		sb.WriteString(lang.t("detail.next"))
+		sb.WriteString(s.Next().StringIn(lang))
 	}
 
 	return sb.String()
 }
 
-// Next returns the closest upcoming StockingData based on the current time
+// Next returns the closest upcoming StockingData based on the current time. A Week that includes today is
+// still upcoming since the stocking might not have happened yet
 func (s Calendar) Next() Week {
-	now := getNow().In(azTime)
+	today := s.today()
 
 	for _, data := range s.Data {
 		if data.Stock == NoneFish || data.Stock == UnknownFish {
 			continue
 		}
-		if data.Time().After(now) {
+		if !data.End().Before(today) {
 			return data
 		}
 	}
@@ -178,15 +329,16 @@ func (s Calendar) Next() Week {
 	return Week{}
 }
 
-// Last returns the most recent StockingData based on the current time
+// Last returns the most recent StockingData based on the current time. Only Weeks that ended before today
+// are included
 func (s Calendar) Last() Week {
-	now := getNow().In(azTime)
+	today := s.today()
 
 	for _, data := range slices.Backward(s.Data) {
 		if data.Stock == NoneFish {
 			continue
 		}
-		if data.Time().Before(now) {
+		if data.End().Before(today) {
 			return data
 		}
 	}
@@ -239,11 +391,38 @@ func (s StockingData) SortLast() {
 	})
 }
 
+// Option configures optional behavior when getting stocking data
+type Option func(*sheet)
+
+// WithParseErrorHandler sets a function that is called for each water row that cannot be parsed. Rows that
+// fail to parse are skipped. By default, these errors are logged
+func WithParseErrorHandler(handler func(waterName string, err error)) Option {
+	return func(s *sheet) {
+		s.onParseError = handler
+	}
+}
+
+// WithSeasonHandler sets a function that is called with the Season that the data is from after it is chosen
+func WithSeasonHandler(handler func(Season)) Option {
+	return func(s *sheet) {
+		s.onSeason = handler
+	}
+}
+
+// WithSeason gets data for a specific Season instead of the current one. Use Seasons to list the available
+// Seasons or ParseSeason to create one from a sheet name
+func WithSeason(season Season) Option {
+	return func(s *sheet) {
+		s.season = season
+	}
+}
+
 type sheet struct {
-	srv             *sheets.Service
-	spreadsheetID   string
-	sheetName       string
-	backupSheetName string
+	srv           *sheets.Service
+	spreadsheet   *Spreadsheet
+	program       Program
+	spreadsheetID string
+	season        Season
 
 	// A1 notation range to get water name and schedule
 	scheduleRange string
@@ -252,92 +431,116 @@ type sheet struct {
 
 	// winter schedule has a column deleted from the sheet, but it shows up as empty in the raw data
 	skipDataCol int
+
+	onParseError func(waterName string, err error)
+	onSeason     func(Season)
 }
 
-// create a new Sheet depending on the required program
+// create a new Sheet depending on the required program. The Season is chosen later unless it is set by an Option
 func newSheet(srv *sheets.Service, program Program) *sheet {
-	year := time.Now().Year()
 	switch program {
 	case CFProgram:
 		return &sheet{
 			srv:           srv,
+			program:       program,
 			spreadsheetID: cfpStockingSheetID,
-			sheetName:     cfpStockingSheetName,
 			scheduleRange: "A11:Z",
 			dateRange:     "B8:9",
 			skipDataCol:   -1,
 		}
 	case WinterProgram:
 		return &sheet{
-			srv:             srv,
-			spreadsheetID:   winterStockingSheetID,
-			sheetName:       fmt.Sprintf(winterStockingSheetName, year, year+1),
-			backupSheetName: fmt.Sprintf(winterStockingSheetName, year-1, year),
-			scheduleRange:   "A9:AD",
-			dateRange:       "B4:5",
-			skipDataCol:     5,
+			srv:           srv,
+			program:       program,
+			spreadsheetID: winterStockingSheetID,
+			scheduleRange: "A9:AD",
+			dateRange:     "B4:5",
+			skipDataCol:   5,
 		}
 	case SpringSummerProgram:
 		return &sheet{
-			srv:             srv,
-			spreadsheetID:   springSummerStockingSheetID,
-			sheetName:       fmt.Sprintf(springSummerStockingSheetName, year),
-			backupSheetName: fmt.Sprintf(springSummerStockingSheetName, year-1),
-			scheduleRange:   "A9:AD",
-			dateRange:       "B4:5",
-			skipDataCol:     5,
+			srv:           srv,
+			program:       program,
+			spreadsheetID: springSummerStockingSheetID,
+			scheduleRange: "A9:AD",
+			dateRange:     "B4:5",
+			skipDataCol:   5,
 		}
 	default:
 		return nil
 	}
 }
 
-func (s *sheet) getDataForWaters(waterNames []string) (StockingData, error) {
+func (s *sheet) getDataForWaters(ctx context.Context, waterNames []string) (StockingData, error) {
 	lowerCaseWaterNames := []string{}
 	for _, w := range waterNames {
 		lowerCaseWaterNames = append(lowerCaseWaterNames, strings.ToLower(w))
 	}
 
-	stockingCalendar, err := s.initializeCalendar()
+	stockingCalendar, err := s.initializeCalendar(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing calendar: %w", err)
 	}
 
-	data, err := s.getStockingData(stockingCalendar, lowerCaseWaterNames)
+	data, err := s.getStockingData(ctx, stockingCalendar, lowerCaseWaterNames)
 	if err != nil {
 		return nil, fmt.Errorf("error finding water rows: %w", err)
 	}
+	if len(waterNames) > 0 && len(data) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoMatchingWaters, strings.Join(waterNames, ", "))
+	}
 	return data, nil
 }
 
-// getSheet attempts to get the sheet and uses the backup name if it fails
-// set the input to true to use the main name and fall back to default
-func (s *sheet) getSheet(firstRequest bool, targetRange string) (*sheets.ValueRange, error) {
-	sheetName := s.sheetName
-	if !firstRequest {
-		sheetName = s.backupSheetName
+// getSheet gets the range from the Season's sheet
+func (s *sheet) getSheet(ctx context.Context, targetRange string) (*sheets.ValueRange, error) {
+	readRange := fmt.Sprintf("%s!%s", s.season.Name, targetRange)
+
+	ctx, span := tracer.Start(ctx, "getSheet", trace.WithAttributes(
+		attribute.String("spreadsheet_id", s.spreadsheetID),
+		attribute.String("range", readRange),
+	))
+	defer span.End()
+
+	if s.spreadsheet != nil {
+		values, err := s.spreadsheet.values(s.season.Name, targetRange)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("error getting data from file: %w", err)
+		}
+		span.SetAttributes(attribute.Int("rows", len(values)))
+		return &sheets.ValueRange{Range: readRange, Values: values}, nil
 	}
-	readRange := fmt.Sprintf("%s!%s", sheetName, targetRange)
-	resp, err := s.srv.Spreadsheets.Values.Get(s.spreadsheetID, readRange).Do()
+
+	resp, err := s.srv.Spreadsheets.Values.Get(s.spreadsheetID, readRange).Context(ctx).Do()
 	if err != nil {
-		if firstRequest {
-			return s.getSheet(false, targetRange)
-		}
+		err = classifyError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("error getting data from sheet: %w", err)
 	}
+	span.SetAttributes(attribute.Int("rows", len(resp.Values)))
 
 	return resp, nil
 }
 
 // getStockingData parses a sheet to populate the provided Calendar dates with stocking data for specified waters.
-func (s *sheet) getStockingData(stockingCalendar Calendar, waterNames []string) (StockingData, error) {
-	resp, err := s.getSheet(true, s.scheduleRange)
+func (s *sheet) getStockingData(ctx context.Context, stockingCalendar Calendar, waterNames []string) (StockingData, error) {
+	ctx, span := tracer.Start(ctx, "getStockingData")
+	defer span.End()
+
+	resp, err := s.getSheet(ctx, s.scheduleRange)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("error getting data from sheet: %w", err)
 	}
 
 	result := []Calendar{}
+	region := ""
 	for _, row := range resp.Values {
+		if header, ok := regionHeader(row); ok {
+			region = header
+			continue
+		}
 		if len(row) < 2 {
 			continue
 		}
@@ -352,13 +555,20 @@ func (s *sheet) getStockingData(stockingCalendar Calendar, waterNames []string)
 
 		data, err := s.getDataFromRow(row[1:], stockingCalendar)
 		if err != nil {
-			// TODO: This is not best practice...
-			log.Printf("error getting data for row %q: %v", waterName, err)
+			s.onParseError(waterName, err)
 			continue
 		}
 		data.WaterName = waterName
+		data.Region = region
 		result = append(result, data)
 	}
+	span.SetAttributes(attribute.Int("waters", len(result)))
+
+	if len(waterNames) == 0 && len(result) == 0 {
+		err = fmt.Errorf("%w: no water rows in %s", ErrLayoutMismatch, s.scheduleRange)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
 	return result, nil
 }
@@ -368,7 +578,8 @@ func (s *sheet) getDataFromRow(row []any, stockingCalendar Calendar) (Calendar,
 	// if s.skipDataCol is set, then we will need to skip a col eventually and need to account for this
 	// when appending empty data
 	skippedRows := 0
-	if s.skipDataCol >= 0 {
+	// the skipped col is only in the row if there are enough dates to reach it
+	if s.skipDataCol >= 0 && s.skipDataCol <= len(stockingCalendar.Data) {
 		skippedRows = 1
 	}
 	// empty trailing cols are trimmed, so we append until we have the correct number of cols
@@ -376,7 +587,7 @@ func (s *sheet) getDataFromRow(row []any, stockingCalendar Calendar) (Calendar,
 		row = append(row, "")
 	}
 	if len(stockingCalendar.Data) != len(row)-skippedRows {
-		return Calendar{}, fmt.Errorf("dates and stock rows don't match: %d != %d\n", len(stockingCalendar.Data), len(row))
+		return Calendar{}, fmt.Errorf("%w: dates and stock rows don't match: %d != %d", ErrLayoutMismatch, len(stockingCalendar.Data), len(row))
 	}
 
 	result := Calendar{}
@@ -396,14 +607,20 @@ func (s *sheet) getDataFromRow(row []any, stockingCalendar Calendar) (Calendar,
 }
 
 // initializeCalendar parses the date rows of the Sheet to initialize the Calendar dates
-func (s *sheet) initializeCalendar() (Calendar, error) {
-	resp, err := s.getSheet(true, s.dateRange)
+func (s *sheet) initializeCalendar(ctx context.Context) (Calendar, error) {
+	ctx, span := tracer.Start(ctx, "initializeCalendar")
+	defer span.End()
+
+	resp, err := s.getSheet(ctx, s.dateRange)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return Calendar{}, fmt.Errorf("error getting data from sheet: %w", err)
 	}
 
 	if len(resp.Values) != 2 {
-		return Calendar{}, fmt.Errorf("expected 2 rows but got %d", len(resp.Values))
+		err = fmt.Errorf("%w: expected 2 date rows but got %d", ErrLayoutMismatch, len(resp.Values))
+		span.SetStatus(codes.Error, err.Error())
+		return Calendar{}, err
 	}
 
 	monthCells := resp.Values[0]
@@ -418,68 +635,78 @@ func (s *sheet) initializeCalendar() (Calendar, error) {
 	}
 
 	result := Calendar{}
-	year := chooseCurrentYear(months)
+	// ranged is true for each Week that has its end date from the sheet
+	ranged := []bool{}
 	monthIndex := 0
 	prevDay := -1
 	for _, date := range nonEmptyCells(dayCells) {
-		// split cell for CFP schedule which is formatted like 7-11
-		day, err := strconv.Atoi(strings.Split(date, "-")[0])
-		if err != nil {
+		day, endDay, ok := parseDayRange(date)
+		if !ok {
 			continue
 		}
 
 		// When the current day is less than the previous, we are in a new month
 		if day < prevDay {
 			monthIndex++
-
-			// check for year rollover if it's January
-			if isNewYear(months, monthIndex) {
-				year++
-			}
 		}
 		prevDay = day
 		if monthIndex >= len(months) {
-			return Calendar{}, fmt.Errorf("index out of range: %d", monthIndex)
+			err = fmt.Errorf("%w: more months in the dates than in the header: %d", ErrLayoutMismatch, monthIndex+1)
+			span.SetStatus(codes.Error, err.Error())
+			return Calendar{}, err
 		}
 
 		useYear := months[monthIndex].Year()
 		if useYear == 0 {
-			useYear = year
+			useYear = s.season.yearFor(months[monthIndex].Month())
 		}
-		result.Data = append(result.Data, Week{
+		week := Week{
 			Year:  useYear,
 			Month: months[monthIndex].Month(),
 			Day:   day,
-		})
-	}
-
-	return result, nil
-}
-
-// chooseCurrentYear helps decide what year it is. If a parsed year exists in a month use that. Othwise, determine
-// best guess based on current month range
-func chooseCurrentYear(months []time.Time) int {
-	if len(months) > 0 && months[0].Year() != 0 {
-		return months[0].Year()
+		}
+		if endDay > 0 {
+			end := time.Date(week.Year, week.Month, endDay, 0, 0, 0, 0, azTime)
+			// ranges like 30-3 end in the next month
+			if endDay < day {
+				end = time.Date(week.Year, week.Month+1, endDay, 0, 0, 0, 0, azTime)
+			}
+			week.setEnd(end)
+		}
+		result.Data = append(result.Data, week)
+		ranged = append(ranged, endDay > 0)
 	}
-
-	if len(months) < 2 {
-		return getNow().Year()
+	if len(result.Data) == 0 {
+		err = fmt.Errorf("%w: no dates in %s", ErrLayoutMismatch, s.dateRange)
+		span.SetStatus(codes.Error, err.Error())
+		return Calendar{}, err
 	}
 
-	month1, month2 := months[0].Month(), months[len(months)-1].Month()
-
-	// if first month is > last, then it might be starting with previous year
-	if month1 > month2 {
-		return getNow().Year() - 1
+	// single days are the start of a week that lasts until the next one starts
+	for i := range result.Data {
+		if ranged[i] {
+			continue
+		}
+		end := result.Data[i].Time().AddDate(0, 0, 6)
+		if i+1 < len(result.Data) {
+			nextStart := result.Data[i+1].Time().AddDate(0, 0, -1)
+			if nextStart.Before(end) && !nextStart.Before(result.Data[i].Time()) {
+				end = nextStart
+			}
+		}
+		result.Data[i].setEnd(end)
 	}
 
-	return getNow().Year()
+	return result, nil
 }
 
 // NewService is a shortcut for creating a sheets.Service using an API key and a custom HTTP RoundTripper.
 // If RoundTripper is not provided, http.DefaultTransport will be used
 func NewService(apiKey string, rt http.RoundTripper) (*sheets.Service, error) {
+	if apiKey == "" {
+		return nil, errors.New("missing API key")
+	}
+
 	transport, err := googleHTTP.NewTransport(context.Background(), rt, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("error creating transport: %w", err)
@@ -501,24 +728,90 @@ func NewService(apiKey string, rt http.RoundTripper) (*sheets.Service, error) {
 
 // Get will parse the Google Sheet for the specified Program. If waters are provided, it will only return data
 // for these waters. Otherwise, it provides for all
-func Get(srv *sheets.Service, program Program, waters []string) (StockingData, error) {
+func Get(srv *sheets.Service, program Program, waters []string, opts ...Option) (StockingData, error) {
+	return GetContext(context.Background(), srv, program, waters, opts...)
+}
+
+// GetContext is the same as Get, but uses the provided Context for requests and tracing
+func GetContext(ctx context.Context, srv *sheets.Service, program Program, waters []string, opts ...Option) (StockingData, error) {
+	ctx, span := tracer.Start(ctx, "Get", trace.WithAttributes(
+		attribute.String("program", string(program)),
+		attribute.StringSlice("waters", waters),
+	))
+	defer span.End()
+
 	sheet := newSheet(srv, program)
 	if sheet == nil {
-		return nil, fmt.Errorf("unable to initialize sheet for program %q", program)
+		err := fmt.Errorf("%w: %q", ErrUnknownProgram, program)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	sheet.onParseError = logParseError
+	for _, opt := range opts {
+		opt(sheet)
+	}
+
+	err := sheet.chooseSeason(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error choosing season: %w", err)
+	}
+	span.SetAttributes(attribute.String("season", sheet.season.Name))
+	if sheet.onSeason != nil {
+		sheet.onSeason(sheet.season)
 	}
 
-	stockData, err := sheet.getDataForWaters(waters)
+	stockData, err := sheet.getDataForWaters(ctx, waters)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	return stockData, nil
 }
 
-func isNewYear(months []time.Time, i int) bool {
-	if i >= len(months) {
-		return false
+func logParseError(waterName string, err error) {
+	log.Printf("error getting data for row %q: %v", waterName, err)
+}
+
+// regionHeader checks if the row is a section header like "Flagstaff Area", "Southeast: Tucson, Safford Areas",
+// or "PAYSON AREA WATERS". Headers only have one cell and are not indented like the waters in the section
+func regionHeader(row []any) (string, bool) {
+	if len(row) != 1 {
+		return "", false
+	}
+	raw, ok := row[0].(string)
+	if !ok || raw == "" || raw != strings.TrimLeft(raw, " \t") {
+		return "", false
+	}
+
+	name := strings.TrimSpace(raw)
+	lower := strings.ToLower(name)
+	for _, suffix := range []string{"area", "areas", "waters", "water"} {
+		if strings.HasSuffix(lower, suffix) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// parseDayRange parses a date cell like "7" or a range like "7-11" from the CFP schedule. The end is zero
+// if the cell is a single day
+func parseDayRange(cell string) (int, int, bool) {
+	startStr, endStr, isRange := strings.Cut(strings.ReplaceAll(cell, "–", "-"), "-")
+
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil || start < 1 || start > 31 {
+		return 0, 0, false
+	}
+	if !isRange {
+		return start, 0, true
+	}
+
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil || end < 1 || end > 31 {
+		return start, 0, true
 	}
-	return months[i].Month() == time.January && i > 0 && months[i-1].Month() == time.December
+	return start, end, true
 }
 
 func nonEmptyCells(cells []any) iter.Seq2[int, string] {
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/calvinmclean/azstocker/internal/sheetstest"
 	"github.com/calvinmclean/azstocker/internal/transport"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/api/sheets/v4"
@@ -70,55 +71,57 @@ func TestSortNext(t *testing.T) {
 		Calendar{
 			WaterName: "Tempe - Kiwanis Lake",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "Unknown"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "Catfish"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "Catfish"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "Trout"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "Unknown"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "None"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "Catfish"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "None"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "Catfish"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "None"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "None"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "Trout"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "None"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "None"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "Trout"},
 			},
 		},
 		Calendar{
 			WaterName: "Payson - Green Valley Lakes",
+			Region:    "PAYSON AREA WATERS",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "Trout"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "Trout"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "Trout"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "None"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "None"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "Trout"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "None"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "Trout"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "None"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "Trout"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "Trout"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "None"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "Trout"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "None"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "None"},
 			},
 		},
 		Calendar{
 			WaterName: "Tempe - Tempe Town Lake",
+			Region:    "PHOENIX / MARICOPA AREA EXPANSION WATERS",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "Catfish"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "None"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "None"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "None"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "None"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "Catfish"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "None"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "None"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "None"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "None"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "None"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "None"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "None"},
 			},
 		},
 	}, stockData)
@@ -146,56 +149,58 @@ func TestSortLast(t *testing.T) {
 	assert.Equal(t, StockingData{
 		Calendar{
 			WaterName: "Phoenix - Roadrunner Pond",
+			Region:    "PHOENIX / MARICOPA AREA EXPANSION WATERS",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "Catfish"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "None"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "None"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "None"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "None"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "Catfish"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "None"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "None"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "Trout"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "None"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "None"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "Trout"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "None"},
 			},
 		},
 		Calendar{
 			WaterName: "Buckeye - Sundance Park Lake",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "Catfish"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "Catfish"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "Catfish"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "Trout"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "None"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "Catfish"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "None"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "Catfish"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "None"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "Catfish"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "None"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "Trout"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "None"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "Trout"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "None"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "None"},
 			},
 		},
 		Calendar{
 			WaterName: "St. Johns - Patterson Ponds",
+			Region:    "SHOW LOW / ST JOHNS / EAGAR AREA WATERS",
 			Data: []Week{
-				{Month: 10, Day: 7, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 14, Year: 2024, Stock: "Trout"},
-				{Month: 10, Day: 21, Year: 2024, Stock: "None"},
-				{Month: 10, Day: 28, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 4, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 11, Year: 2024, Stock: "None"},
-				{Month: 11, Day: 18, Year: 2024, Stock: "Trout"},
-				{Month: 11, Day: 25, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 2, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 9, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 16, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 23, Year: 2024, Stock: "None"},
-				{Month: 12, Day: 30, Year: 2024, Stock: "None"},
+				{Month: 10, Day: 7, Year: 2024, EndMonth: 10, EndDay: 11, Stock: "None"},
+				{Month: 10, Day: 14, Year: 2024, EndMonth: 10, EndDay: 18, Stock: "Trout"},
+				{Month: 10, Day: 21, Year: 2024, EndMonth: 10, EndDay: 25, Stock: "None"},
+				{Month: 10, Day: 28, Year: 2024, EndMonth: 11, EndDay: 1, Stock: "None"},
+				{Month: 11, Day: 4, Year: 2024, EndMonth: 11, EndDay: 8, Stock: "None"},
+				{Month: 11, Day: 11, Year: 2024, EndMonth: 11, EndDay: 15, Stock: "None"},
+				{Month: 11, Day: 18, Year: 2024, EndMonth: 11, EndDay: 22, Stock: "Trout"},
+				{Month: 11, Day: 25, Year: 2024, EndMonth: 11, EndDay: 29, Stock: "None"},
+				{Month: 12, Day: 2, Year: 2024, EndMonth: 12, EndDay: 6, Stock: "None"},
+				{Month: 12, Day: 9, Year: 2024, EndMonth: 12, EndDay: 13, Stock: "None"},
+				{Month: 12, Day: 16, Year: 2024, EndMonth: 12, EndDay: 20, Stock: "None"},
+				{Month: 12, Day: 23, Year: 2024, EndMonth: 12, EndDay: 27, Stock: "None"},
+				{Month: 12, Day: 30, Year: 2024, EndMonth: 1, EndDay: 3, Stock: "None"},
 			},
 		},
 	}, stockData)
@@ -218,24 +223,133 @@ func TestNextLast(t *testing.T) {
 	t.Run("Last", func(t *testing.T) {
 		last := stockData[0].Last()
 		assert.Equal(t, Week{
-			Month: time.October,
-			Day:   21,
-			Year:  2024,
-			Stock: Catfish,
+			Month:    time.October,
+			Day:      21,
+			Year:     2024,
+			EndMonth: time.October,
+			EndDay:   25,
+			Stock:    Catfish,
 		}, last)
 	})
 
 	t.Run("Last", func(t *testing.T) {
 		next := stockData[0].Next()
 		assert.Equal(t, Week{
-			Month: time.November,
-			Day:   4,
-			Year:  2024,
-			Stock: Catfish,
+			Month:    time.November,
+			Day:      4,
+			Year:     2024,
+			EndMonth: time.November,
+			EndDay:   8,
+			Stock:    Catfish,
 		}, next)
 	})
 }
 
+func TestParseDayRange(t *testing.T) {
+	tests := []struct {
+		input string
+		start int
+		end   int
+		ok    bool
+	}{
+		{"7", 7, 0, true},
+		{"7-11", 7, 11, true},
+		{"30 - 3", 30, 3, true},
+		{"28–1", 28, 1, true},
+		{"7-", 7, 0, true},
+		{"week", 0, 0, false},
+		{"0", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			start, end, ok := parseDayRange(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestWeekRange(t *testing.T) {
+	week := Week{Year: 2024, Month: time.July, Day: 7, EndMonth: time.July, EndDay: 11, Stock: Trout}
+	calendar := Calendar{Data: []Week{
+		{Year: 2024, Month: time.June, Day: 30, EndMonth: time.July, EndDay: 3, Stock: Catfish},
+		week,
+		{Year: 2024, Month: time.July, Day: 14, EndMonth: time.July, EndDay: 18, Stock: Trout},
+	}}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		next      Week
+		last      Week
+		humanTime string
+	}{
+		{"Before", time.Date(2024, time.July, 5, 12, 0, 0, 0, azTime), week, calendar.Data[0], "1 day from now"},
+		{"FirstDay", time.Date(2024, time.July, 7, 8, 0, 0, 0, azTime), week, calendar.Data[0], "today"},
+		{"SecondDay", time.Date(2024, time.July, 8, 8, 0, 0, 0, azTime), week, calendar.Data[0], "this week"},
+		{"LastDay", time.Date(2024, time.July, 11, 23, 0, 0, 0, azTime), week, calendar.Data[0], "this week"},
+		// 6AM UTC on July 12 is still July 11 in Arizona
+		{"LastDayUTC", time.Date(2024, time.July, 12, 6, 0, 0, 0, time.UTC), week, calendar.Data[0], "this week"},
+		{"After", time.Date(2024, time.July, 12, 8, 0, 0, 0, azTime), calendar.Data[2], week, "5 days ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getNow = func() time.Time { return tt.now }
+			defer func() { getNow = time.Now }()
+
+			assert.Equal(t, tt.next, calendar.Next())
+			assert.Equal(t, tt.last, calendar.Last())
+			assert.Equal(t, tt.humanTime, week.HumanTime())
+		})
+	}
+
+	t.Run("DateIn", func(t *testing.T) {
+		assert.Equal(t, "2024 July 7-11", week.DateIn(English))
+		assert.Equal(t, "2024 junio 30 - julio 3", calendar.Data[0].DateIn(Spanish))
+		assert.Equal(t, "2024 December 30 - 2025 January 3", Week{Year: 2024, Month: time.December, Day: 30, EndMonth: time.January, EndDay: 3}.DateIn(English))
+		assert.Equal(t, "2024 July 7", Week{Year: 2024, Month: time.July, Day: 7}.DateIn(English))
+	})
+
+	t.Run("End", func(t *testing.T) {
+		assert.Equal(t, time.Date(2025, time.January, 3, 0, 0, 0, 0, azTime), Week{Year: 2024, Month: time.December, Day: 30, EndMonth: time.January, EndDay: 3}.End())
+		assert.Equal(t, time.Date(2024, time.July, 13, 0, 0, 0, 0, azTime), Week{Year: 2024, Month: time.July, Day: 7}.End())
+	})
+}
+
+func TestLocalizedWeek(t *testing.T) {
+	getNow = func() time.Time {
+		return time.Date(2024, time.November, 2, 13, 0, 0, 0, time.UTC)
+	}
+	defer func() { getNow = time.Now }()
+
+	week := Week{Month: time.October, Day: 21, Year: 2024, Stock: Catfish}
+
+	tests := []struct {
+		lang      Language
+		humanTime string
+		str       string
+	}{
+		{English, "1 week ago", `2024 October 21: "Catfish"`},
+		{Spanish, "hace 1 semana", `2024 octubre 21: "Bagre"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.lang), func(t *testing.T) {
+			assert.Equal(t, tt.humanTime, week.HumanTimeIn(tt.lang))
+			assert.Equal(t, tt.str, week.StringIn(tt.lang))
+		})
+	}
+
+	t.Run("DetailFormat", func(t *testing.T) {
+		calendar := Calendar{WaterName: "Lake", Data: []Week{week}}
+		assert.Equal(t, "Última: 2024 octubre 21: \"Bagre\"\nPróxima:Sin Datos", calendar.DetailFormatIn(Spanish, false, false, true, true))
+		assert.Equal(t, calendar.DetailFormat(false, false, true, true), calendar.DetailFormatIn(English, false, false, true, true))
+	})
+}
+
 func createTestService(t *testing.T, cassetteName string) (*sheets.Service, *recorder.Recorder) {
 	t.Helper()
 
@@ -274,7 +388,7 @@ func createTestService(t *testing.T, cassetteName string) (*sheets.Service, *rec
 		recorder.WithMode(recorder.ModeRecordOnly)(r)
 	}
 
-	cacheControl := transport.NewCacheControl(time.Minute, r)
+	cacheControl := transport.NewCacheControl(time.Minute, sheetstest.WithMetadata(r))
 	srv, err := NewService(apiKey, cacheControl)
 	assert.NoError(t, err)
 
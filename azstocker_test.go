@@ -1,6 +1,7 @@
 package azstocker_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/sheetstest"
 	"github.com/calvinmclean/azstocker/internal/transport"
 
 	"github.com/stretchr/testify/assert"
@@ -37,174 +39,701 @@ func TestGet(t *testing.T) {
 			[]azstocker.Calendar{
 				{
 					WaterName: "LOWER SALT RIVER",
+					Region:    "Phoenix Area",
 					Data: []azstocker.Week{
 						// October
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   1,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.October,
+							Day:      1,
+							EndMonth: time.October,
+							EndDay:   6,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   7,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.October,
+							Day:      7,
+							EndMonth: time.October,
+							EndDay:   13,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   14,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.October,
+							Day:      14,
+							EndMonth: time.October,
+							EndDay:   20,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   21,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.October,
+							Day:      21,
+							EndMonth: time.October,
+							EndDay:   27,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   28,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.October,
+							Day:      28,
+							EndMonth: time.November,
+							EndDay:   3,
+							Stock:    azstocker.Trout,
 						},
 						// November
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   4,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.November,
+							Day:      4,
+							EndMonth: time.November,
+							EndDay:   10,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   11,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.November,
+							Day:      11,
+							EndMonth: time.November,
+							EndDay:   17,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   18,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.November,
+							Day:      18,
+							EndMonth: time.November,
+							EndDay:   24,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   25,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.November,
+							Day:      25,
+							EndMonth: time.December,
+							EndDay:   1,
+							Stock:    azstocker.Trout,
 						},
 						// December
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   2,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      2,
+							EndMonth: time.December,
+							EndDay:   8,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   9,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      9,
+							EndMonth: time.December,
+							EndDay:   15,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   16,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      16,
+							EndMonth: time.December,
+							EndDay:   22,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   23,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      23,
+							EndMonth: time.December,
+							EndDay:   29,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   30,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.December,
+							Day:      30,
+							EndMonth: time.January,
+							EndDay:   5,
+							Stock:    azstocker.NoneFish,
 						},
 						// January
 						{
-							Year:  2025,
-							Month: time.January,
-							Day:   6,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.January,
+							Day:      6,
+							EndMonth: time.January,
+							EndDay:   12,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.January,
-							Day:   13,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.January,
+							Day:      13,
+							EndMonth: time.January,
+							EndDay:   19,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.January,
-							Day:   20,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.January,
+							Day:      20,
+							EndMonth: time.January,
+							EndDay:   26,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.January,
-							Day:   27,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.January,
+							Day:      27,
+							EndMonth: time.February,
+							EndDay:   2,
+							Stock:    azstocker.Trout,
 						},
 						// February
 						{
-							Year:  2025,
-							Month: time.February,
-							Day:   3,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.February,
+							Day:      3,
+							EndMonth: time.February,
+							EndDay:   9,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.February,
-							Day:   10,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.February,
+							Day:      10,
+							EndMonth: time.February,
+							EndDay:   16,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.February,
-							Day:   17,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.February,
+							Day:      17,
+							EndMonth: time.February,
+							EndDay:   23,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.February,
-							Day:   24,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.February,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   2,
+							Stock:    azstocker.Trout,
 						},
 						// March
 						{
-							Year:  2025,
-							Month: time.March,
-							Day:   3,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.March,
+							Day:      3,
+							EndMonth: time.March,
+							EndDay:   9,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.March,
-							Day:   10,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.March,
+							Day:      10,
+							EndMonth: time.March,
+							EndDay:   16,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.March,
-							Day:   17,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.March,
+							Day:      17,
+							EndMonth: time.March,
+							EndDay:   23,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.March,
-							Day:   24,
-							Stock: azstocker.Trout,
+							Year:     2025,
+							Month:    time.March,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   30,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2025,
-							Month: time.March,
-							Day:   31,
-							Stock: azstocker.NoneFish,
+							Year:     2025,
+							Month:    time.March,
+							Day:      31,
+							EndMonth: time.April,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
+						},
+					},
+				},
+			},
+		},
+		{
+			"Winter_Tucson",
+			winterFixture,
+			azstocker.WinterProgram,
+			[]string{"ROSE CANYON LAKE"},
+			[]azstocker.Calendar{
+				{
+					WaterName: "ROSE CANYON LAKE",
+					Region:    "Southeast: Tucson, Safford Areas",
+					Data: []azstocker.Week{
+						// October
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      1,
+							EndMonth: time.October,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      7,
+							EndMonth: time.October,
+							EndDay:   13,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      14,
+							EndMonth: time.October,
+							EndDay:   20,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      21,
+							EndMonth: time.October,
+							EndDay:   27,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      28,
+							EndMonth: time.November,
+							EndDay:   3,
+							Stock:    azstocker.NoneFish,
+						},
+						// November
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      4,
+							EndMonth: time.November,
+							EndDay:   10,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      11,
+							EndMonth: time.November,
+							EndDay:   17,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      18,
+							EndMonth: time.November,
+							EndDay:   24,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      25,
+							EndMonth: time.December,
+							EndDay:   1,
+							Stock:    azstocker.NoneFish,
+						},
+						// December
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      2,
+							EndMonth: time.December,
+							EndDay:   8,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      9,
+							EndMonth: time.December,
+							EndDay:   15,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      16,
+							EndMonth: time.December,
+							EndDay:   22,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      23,
+							EndMonth: time.December,
+							EndDay:   29,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      30,
+							EndMonth: time.January,
+							EndDay:   5,
+							Stock:    azstocker.NoneFish,
+						},
+						// January
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      6,
+							EndMonth: time.January,
+							EndDay:   12,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      13,
+							EndMonth: time.January,
+							EndDay:   19,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      20,
+							EndMonth: time.January,
+							EndDay:   26,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      27,
+							EndMonth: time.February,
+							EndDay:   2,
+							Stock:    azstocker.NoneFish,
+						},
+						// February
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      3,
+							EndMonth: time.February,
+							EndDay:   9,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      10,
+							EndMonth: time.February,
+							EndDay:   16,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      17,
+							EndMonth: time.February,
+							EndDay:   23,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   2,
+							Stock:    azstocker.NoneFish,
+						},
+						// March
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      3,
+							EndMonth: time.March,
+							EndDay:   9,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      10,
+							EndMonth: time.March,
+							EndDay:   16,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      17,
+							EndMonth: time.March,
+							EndDay:   23,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   30,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      31,
+							EndMonth: time.April,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
+						},
+					},
+				},
+			},
+		},
+		{
+			"Winter_Yuma",
+			winterFixture,
+			azstocker.WinterProgram,
+			[]string{"FORTUNA LAKE"},
+			[]azstocker.Calendar{
+				{
+					WaterName: "FORTUNA LAKE",
+					Region:    "West/SW: Parker, Yuma, Gila Bend Areas",
+					Data: []azstocker.Week{
+						// October
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      1,
+							EndMonth: time.October,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      7,
+							EndMonth: time.October,
+							EndDay:   13,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      14,
+							EndMonth: time.October,
+							EndDay:   20,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      21,
+							EndMonth: time.October,
+							EndDay:   27,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.October,
+							Day:      28,
+							EndMonth: time.November,
+							EndDay:   3,
+							Stock:    azstocker.NoneFish,
+						},
+						// November
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      4,
+							EndMonth: time.November,
+							EndDay:   10,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      11,
+							EndMonth: time.November,
+							EndDay:   17,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      18,
+							EndMonth: time.November,
+							EndDay:   24,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.November,
+							Day:      25,
+							EndMonth: time.December,
+							EndDay:   1,
+							Stock:    azstocker.NoneFish,
+						},
+						// December
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      2,
+							EndMonth: time.December,
+							EndDay:   8,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      9,
+							EndMonth: time.December,
+							EndDay:   15,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      16,
+							EndMonth: time.December,
+							EndDay:   22,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      23,
+							EndMonth: time.December,
+							EndDay:   29,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2024,
+							Month:    time.December,
+							Day:      30,
+							EndMonth: time.January,
+							EndDay:   5,
+							Stock:    azstocker.NoneFish,
+						},
+						// January
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      6,
+							EndMonth: time.January,
+							EndDay:   12,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      13,
+							EndMonth: time.January,
+							EndDay:   19,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      20,
+							EndMonth: time.January,
+							EndDay:   26,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.January,
+							Day:      27,
+							EndMonth: time.February,
+							EndDay:   2,
+							Stock:    azstocker.NoneFish,
+						},
+						// February
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      3,
+							EndMonth: time.February,
+							EndDay:   9,
+							Stock:    azstocker.Trout,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      10,
+							EndMonth: time.February,
+							EndDay:   16,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      17,
+							EndMonth: time.February,
+							EndDay:   23,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.February,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   2,
+							Stock:    azstocker.NoneFish,
+						},
+						// March
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      3,
+							EndMonth: time.March,
+							EndDay:   9,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      10,
+							EndMonth: time.March,
+							EndDay:   16,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      17,
+							EndMonth: time.March,
+							EndDay:   23,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      24,
+							EndMonth: time.March,
+							EndDay:   30,
+							Stock:    azstocker.NoneFish,
+						},
+						{
+							Year:     2025,
+							Month:    time.March,
+							Day:      31,
+							EndMonth: time.April,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
 						},
 					},
 				},
@@ -221,84 +750,110 @@ func TestGet(t *testing.T) {
 					Data: []azstocker.Week{
 						// October
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   7,
-							Stock: azstocker.Catfish,
+							Year:     2024,
+							Month:    time.October,
+							Day:      7,
+							EndMonth: time.October,
+							EndDay:   11,
+							Stock:    azstocker.Catfish,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   14,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.October,
+							Day:      14,
+							EndMonth: time.October,
+							EndDay:   18,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   21,
-							Stock: azstocker.Catfish,
+							Year:     2024,
+							Month:    time.October,
+							Day:      21,
+							EndMonth: time.October,
+							EndDay:   25,
+							Stock:    azstocker.Catfish,
 						},
 						{
-							Year:  2024,
-							Month: time.October,
-							Day:   28,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.October,
+							Day:      28,
+							EndMonth: time.November,
+							EndDay:   1,
+							Stock:    azstocker.NoneFish,
 						},
 						// November
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   4,
-							Stock: azstocker.Catfish,
+							Year:     2024,
+							Month:    time.November,
+							Day:      4,
+							EndMonth: time.November,
+							EndDay:   8,
+							Stock:    azstocker.Catfish,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   11,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.November,
+							Day:      11,
+							EndMonth: time.November,
+							EndDay:   15,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   18,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.November,
+							Day:      18,
+							EndMonth: time.November,
+							EndDay:   22,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.November,
-							Day:   25,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.November,
+							Day:      25,
+							EndMonth: time.November,
+							EndDay:   29,
+							Stock:    azstocker.NoneFish,
 						},
 						// December
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   2,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.December,
+							Day:      2,
+							EndMonth: time.December,
+							EndDay:   6,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   9,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      9,
+							EndMonth: time.December,
+							EndDay:   13,
+							Stock:    azstocker.Trout,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   16,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.December,
+							Day:      16,
+							EndMonth: time.December,
+							EndDay:   20,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   23,
-							Stock: azstocker.NoneFish,
+							Year:     2024,
+							Month:    time.December,
+							Day:      23,
+							EndMonth: time.December,
+							EndDay:   27,
+							Stock:    azstocker.NoneFish,
 						},
 						{
-							Year:  2024,
-							Month: time.December,
-							Day:   30,
-							Stock: azstocker.Trout,
+							Year:     2024,
+							Month:    time.December,
+							Day:      30,
+							EndMonth: time.January,
+							EndDay:   3,
+							Stock:    azstocker.Trout,
 						},
 					},
 				},
@@ -335,6 +890,7 @@ func TestGetHTTPCache(t *testing.T) {
 		assert.NoError(t, r.Stop())
 	}()
 
+	// recorded requests are for the dates and schedule. Sheet names come from sheetstest.WithMetadata
 	_, err := azstocker.Get(srv, azstocker.WinterProgram, []string{})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, numRequests)
@@ -344,6 +900,38 @@ func TestGetHTTPCache(t *testing.T) {
 	assert.Equal(t, 2, numRequests, "no new requests should be created for the 2nd request")
 }
 
+func TestSeasons(t *testing.T) {
+	srv, r := createTestService(t, winterFixture)
+	defer func() {
+		assert.NoError(t, r.Stop())
+	}()
+
+	seasons, err := azstocker.Seasons(context.Background(), srv, azstocker.WinterProgram)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2023-24 Winter", "2024-25 Winter"}, []string{seasons[0].Name, seasons[1].Name})
+
+	t.Run("WithSeason", func(t *testing.T) {
+		stockData, err := azstocker.Get(srv, azstocker.WinterProgram, []string{"LOWER SALT RIVER"}, azstocker.WithSeason(seasons[1]))
+		assert.NoError(t, err)
+		assert.Len(t, stockData, 1)
+		assert.Equal(t, azstocker.Week{Year: 2025, Month: time.March, Day: 31, EndMonth: time.April, EndDay: 6, Stock: azstocker.NoneFish}, stockData[0].Data[len(stockData[0].Data)-1])
+	})
+
+	t.Run("WithSeasonHandler", func(t *testing.T) {
+		var chosen azstocker.Season
+		_, err := azstocker.Get(srv, azstocker.WinterProgram, []string{"LOWER SALT RIVER"}, azstocker.WithSeason(seasons[1]), azstocker.WithSeasonHandler(func(season azstocker.Season) {
+			chosen = season
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, seasons[1], chosen)
+	})
+
+	t.Run("WrongProgram", func(t *testing.T) {
+		_, err := azstocker.Get(srv, azstocker.CFProgram, nil, azstocker.WithSeason(seasons[1]))
+		assert.EqualError(t, err, `error choosing season: season "2024-25 Winter" is not for program "cfp"`)
+	})
+}
+
 func createTestService(t *testing.T, cassetteName string) (*sheets.Service, *recorder.Recorder) {
 	t.Helper()
 
@@ -382,7 +970,7 @@ func createTestService(t *testing.T, cassetteName string) (*sheets.Service, *rec
 		recorder.WithMode(recorder.ModeRecordOnly)(r)
 	}
 
-	cacheControl := transport.NewCacheControl(time.Minute, r)
+	cacheControl := transport.NewCacheControl(time.Minute, sheetstest.WithMetadata(r))
 	srv, err := azstocker.NewService(apiKey, cacheControl)
 	assert.NoError(t, err)
 
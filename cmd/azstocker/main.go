@@ -1,32 +1,71 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/export"
 	"github.com/calvinmclean/azstocker/internal/server"
+	"github.com/calvinmclean/azstocker/internal/tracing"
 	"github.com/calvinmclean/azstocker/internal/transport"
 
 	"github.com/urfave/cli/v2"
+	"google.golang.org/api/sheets/v4"
 )
 
 func main() {
-	var debug, showNext, showLast, showAllStock, showAll bool
-	var apiKey, programStr, addr, cacheDir, pushoverAppToken, pushoverRecipientToken, urlBase string
+	var debug, showNext, showLast, showAllStock, showAll, onlyUpcoming, includeArchived bool
+	var apiKey, programStr, langStr, seasonStr, fromStr, toStr, sinceStr, dateStr, formatStr, outputPath, addr, cacheDir, pushoverAppToken, pushoverRecipientToken, urlBase string
 	var cacheMaxAge time.Duration
-	var waters []string
+	var rateLimit transport.RateLimitConfig
+	var tracingConfig tracing.Config
+	serverTimeouts := server.DefaultTimeouts
+	metricsConfig := server.DefaultMetricsConfig
+	clientRateLimit := server.DefaultClientRateLimit
+	var trustProxyHeaders bool
+	var listsDir, scheduleDir, changesFile string
+	shutdownTracing := func(context.Context) error { return nil }
+	var waters, species, stockingReports, fromFiles, programStrs []string
+	var days int
+
+	// openSource reads the schedules from the --from-file files if they are set. Otherwise, it creates a
+	// Sheets service
+	openSource := func() (*sheets.Service, []azstocker.Option, error) {
+		if len(fromFiles) > 0 {
+			spreadsheet, err := azstocker.OpenSpreadsheet(fromFiles...)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error opening files: %w", err)
+			}
+			return nil, []azstocker.Option{azstocker.WithSpreadsheet(spreadsheet)}, nil
+		}
+
+		rt := setupCacheControl(cacheMaxAge, cacheDir, transport.NewRateLimit(rateLimit, nil))
+		if debug {
+			rt = transport.Log(rt)
+		}
+
+		srv, err := azstocker.NewService(apiKey, rt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating Sheets service: %w", err)
+		}
+		return srv, nil, nil
+	}
+
 	app := &cli.App{
 		Name: "azstocker",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "debug", Usage: "enable debug logs", Destination: &debug},
 			&cli.StringFlag{
 				Name:        "api-key",
-				Required:    true,
-				Usage:       "Google API key to access Sheets",
+				Usage:       "Google API key to access Sheets. It is not needed when reading schedules from files",
 				EnvVars:     []string{"API_KEY"},
 				Destination: &apiKey,
 			},
@@ -42,6 +81,53 @@ func main() {
 				Destination: &cacheDir,
 				EnvVars:     []string{"CACHE_DIR"},
 			},
+			&cli.Float64Flag{
+				Name:        "rate-limit",
+				Usage:       "max requests per minute to the Sheets API. Set to 0 to disable",
+				Value:       60,
+				Destination: &rateLimit.PerMinute,
+				EnvVars:     []string{"RATE_LIMIT"},
+			},
+			&cli.IntFlag{
+				Name:        "rate-limit-burst",
+				Usage:       "max requests to the Sheets API that can be made at once",
+				Value:       10,
+				Destination: &rateLimit.Burst,
+			},
+			&cli.DurationFlag{
+				Name:        "rate-limit-wait",
+				Usage:       "max time a request will wait for the rate limiter before it is rejected. Set to 0 to reject immediately",
+				Value:       5 * time.Second,
+				Destination: &rateLimit.MaxWait,
+			},
+			&cli.StringFlag{
+				Name:        "otlp-endpoint",
+				Usage:       "host:port of an OTLP HTTP collector to export traces to. Tracing is disabled if unset",
+				Destination: &tracingConfig.Endpoint,
+				EnvVars:     []string{"OTLP_ENDPOINT"},
+			},
+			&cli.BoolFlag{
+				Name:        "otlp-insecure",
+				Usage:       "disable TLS when exporting traces, which is useful for a local collector",
+				Destination: &tracingConfig.Insecure,
+			},
+			&cli.Float64Flag{
+				Name:        "trace-sample-ratio",
+				Usage:       "fraction of traces to sample",
+				Value:       1,
+				Destination: &tracingConfig.SampleRatio,
+			},
+		},
+		Before: func(c *cli.Context) error {
+			var err error
+			shutdownTracing, err = tracing.Setup(c.Context, tracingConfig)
+			if err != nil {
+				return fmt.Errorf("error setting up tracing: %w", err)
+			}
+			return nil
+		},
+		After: func(c *cli.Context) error {
+			return shutdownTracing(context.Background())
 		},
 		DefaultCommand: "server",
 		Commands: []*cli.Command{
@@ -69,6 +155,44 @@ func main() {
 						Usage:       "AZ GFD Fishing program to search (CFP, Spring/Summer, or Winter)",
 						Destination: &programStr,
 					},
+					&cli.StringFlag{
+						Name:        "lang",
+						Usage:       "language for the output (en or es)",
+						Value:       string(azstocker.English),
+						Destination: &langStr,
+						EnvVars:     []string{"AZSTOCKER_LANG"},
+					},
+					&cli.StringFlag{
+						Name:        "season",
+						Usage:       "name of the season to get, like \"2024-25 Winter\". By default, the current season is used",
+						Destination: &seasonStr,
+					},
+					&cli.StringFlag{Name: "from", Usage: "only show stocking weeks that end on or after this date, like 2024-10-21", Destination: &fromStr},
+					&cli.StringFlag{Name: "to", Usage: "only show stocking weeks that start on or before this date, like 2024-10-21", Destination: &toStr},
+					&cli.IntFlag{Name: "days", Usage: "only show stockings in the next number of days", Destination: &days},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "species",
+							Usage: "only show stockings of these fish (trout or catfish)",
+						},
+						Destination: &species,
+					},
+					&cli.BoolFlag{Name: "upcoming", Usage: "only show waters with an upcoming stocking", Destination: &onlyUpcoming},
+					&cli.StringFlag{Name: "since", Usage: "only show waters stocked on or after this date, like 2024-10-21", Destination: &sinceStr},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "stocking-report",
+							Usage: "file or URL of an AZGFD weekly stocking report CSV or XLSX to show if scheduled stockings happened",
+						},
+						Destination: &stockingReports,
+					},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "from-file",
+							Usage: "read the schedule from a downloaded CSV or XLSX copy of the spreadsheet instead of Google Sheets",
+						},
+						Destination: &fromFiles,
+					},
 				},
 				Action: func(c *cli.Context) error {
 					program, err := azstocker.ParseProgram(programStr)
@@ -76,24 +200,228 @@ func main() {
 						return err
 					}
 
-					rt := setupCacheControl(cacheMaxAge, cacheDir)
-					if debug {
-						rt = transport.Log(rt)
+					lang, err := azstocker.ParseLanguage(langStr)
+					if err != nil {
+						return err
 					}
 
-					srv, err := azstocker.NewService(apiKey, rt)
+					filterOpts := azstocker.FilterOptions{
+						From:     fromStr,
+						To:       toStr,
+						Species:  species,
+						Upcoming: onlyUpcoming,
+						Since:    sinceStr,
+					}
+					if c.IsSet("days") {
+						filterOpts.Days = strconv.Itoa(days)
+					}
+					filters, err := azstocker.ParseFilters(filterOpts)
+					if err != nil {
+						return err
+					}
+
+					var opts []azstocker.Option
+					if seasonStr != "" {
+						season, err := azstocker.ParseSeason(program, seasonStr)
+						if err != nil {
+							return err
+						}
+						opts = append(opts, azstocker.WithSeason(season))
+					}
+
+					srv, sourceOpts, err := openSource()
 					if err != nil {
-						return fmt.Errorf("error creating Sheets service: %w", err)
+						return err
 					}
+					opts = append(opts, sourceOpts...)
 
-					stockData, err := azstocker.Get(srv, program, waters)
+					stockData, err := azstocker.GetContext(c.Context, srv, program, waters, opts...)
 					if err != nil {
 						return fmt.Errorf("error getting stocking data: %w", err)
 					}
+					stockData = stockData.Filter(filters...)
+
+					if len(stockingReports) > 0 {
+						reports := []azstocker.Report{}
+						for _, source := range stockingReports {
+							report, err := azstocker.LoadReport(c.Context, source)
+							if err != nil {
+								return fmt.Errorf("error loading stocking report %q: %w", source, err)
+							}
+							reports = append(reports, report)
+						}
+						stockData = stockData.Reconcile(reports...)
+					}
 
 					for waterName, calendar := range stockData {
 						fmt.Println(waterName)
-						fmt.Println(calendar.DetailFormat(showAll, showAllStock, showNext, showLast))
+						fmt.Println(calendar.DetailFormatIn(lang, showAll, showAllStock, showNext, showLast))
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "seasons",
+				Description: "list the seasons that are available for a program",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "program",
+						Required:    true,
+						Aliases:     []string{"p"},
+						Usage:       "AZ GFD Fishing program to search (CFP, Spring/Summer, or Winter)",
+						Destination: &programStr,
+					},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "from-file",
+							Usage: "read the seasons from a downloaded CSV or XLSX copy of the spreadsheet instead of Google Sheets",
+						},
+						Destination: &fromFiles,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					program, err := azstocker.ParseProgram(programStr)
+					if err != nil {
+						return err
+					}
+
+					srv, opts, err := openSource()
+					if err != nil {
+						return err
+					}
+
+					seasons, err := azstocker.Seasons(c.Context, srv, program, opts...)
+					if err != nil {
+						return fmt.Errorf("error getting seasons: %w", err)
+					}
+
+					current, _ := azstocker.CurrentSeason(seasons, azstocker.Now())
+					for _, season := range seasons {
+						if season == current {
+							fmt.Printf("%s (current)\n", season)
+							continue
+						}
+						fmt.Println(season)
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "week",
+				Description: "list every water scheduled to be stocked this week in all programs",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "date", Usage: "show the week that includes this date, like 2024-10-21. By default, the current week is used", Destination: &dateStr},
+					&cli.StringFlag{
+						Name:        "lang",
+						Usage:       "language for the output (en or es)",
+						Value:       string(azstocker.English),
+						Destination: &langStr,
+						EnvVars:     []string{"AZSTOCKER_LANG"},
+					},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "from-file",
+							Usage: "read the schedules from downloaded CSV or XLSX copies of the spreadsheets instead of Google Sheets. Programs without a schedule in the files are skipped",
+						},
+						Destination: &fromFiles,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					lang, err := azstocker.ParseLanguage(langStr)
+					if err != nil {
+						return err
+					}
+
+					date := azstocker.Now()
+					if dateStr != "" {
+						date, err = azstocker.ParseDate(dateStr)
+						if err != nil {
+							return err
+						}
+					}
+
+					srv, opts, err := openSource()
+					if err != nil {
+						return err
+					}
+
+					digest, err := azstocker.GetWeekDigest(c.Context, srv, date, opts...)
+					if err != nil {
+						return fmt.Errorf("error getting stocking data: %w", err)
+					}
+
+					fmt.Println(digest.StringIn(lang))
+					return nil
+				},
+			},
+			{
+				Name:        "export",
+				Description: "export stockings for all programs as CSV, Parquet, or SQLite with a row for each water and stocking date",
+				Flags: []cli.Flag{
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:    "program",
+							Aliases: []string{"p"},
+							Usage:   "AZ GFD Fishing programs to export (CFP, Spring/Summer, or Winter). By default, all programs are exported",
+						},
+						Destination: &programStrs,
+					},
+					&cli.StringFlag{
+						Name:        "output",
+						Aliases:     []string{"o"},
+						Usage:       "file to write to. CSV and Parquet are written to stdout if it is not set",
+						Destination: &outputPath,
+					},
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "csv, parquet, or sqlite. By default, it is chosen from the output file extension",
+						Destination: &formatStr,
+					},
+					&cli.BoolFlag{Name: "archived", Usage: "include every season in the spreadsheets instead of only the current one", Destination: &includeArchived},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:  "from-file",
+							Usage: "read the schedules from downloaded CSV or XLSX copies of the spreadsheets instead of Google Sheets",
+						},
+						Destination: &fromFiles,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					programs := []azstocker.Program{}
+					for _, p := range programStrs {
+						program, err := azstocker.ParseProgram(p)
+						if err != nil {
+							return err
+						}
+						programs = append(programs, program)
+					}
+
+					format := export.FormatFromPath(outputPath)
+					if formatStr != "" {
+						var err error
+						format, err = export.ParseFormat(formatStr)
+						if err != nil {
+							return err
+						}
+					}
+
+					srv, opts, err := openSource()
+					if err != nil {
+						return err
+					}
+
+					records, skipped, err := azstocker.GetRecords(c.Context, srv, programs, includeArchived, opts...)
+					if err != nil {
+						return fmt.Errorf("error getting stocking data: %w", err)
+					}
+					// data might be written to stdout, so warnings are written to stderr
+					for _, s := range skipped {
+						fmt.Fprintf(c.App.ErrWriter, "skipping season %q: %v\n", s.Season.Name, s.Err)
+					}
+
+					err = export.WriteFile(outputPath, format, records)
+					if err != nil {
+						return fmt.Errorf("error writing export: %w", err)
 					}
 					return nil
 				},
@@ -126,24 +454,151 @@ func main() {
 						Value:       "http://localhost:8080",
 						EnvVars:     []string{"URL_BASE"},
 					},
+					&cli.StringFlag{
+						Name:        "metrics-address",
+						Usage:       "address for a separate metrics listener. Set to empty to disable metrics unless --metrics-mount is used",
+						Value:       metricsConfig.Addr,
+						Destination: &metricsConfig.Addr,
+						EnvVars:     []string{"METRICS_ADDRESS"},
+					},
+					&cli.StringFlag{
+						Name:        "metrics-path",
+						Usage:       "path to serve metrics on",
+						Value:       metricsConfig.Path,
+						Destination: &metricsConfig.Path,
+					},
+					&cli.BoolFlag{
+						Name:        "metrics-mount",
+						Usage:       "serve metrics on the main listener instead of a separate one",
+						Destination: &metricsConfig.Mount,
+					},
+					&cli.StringFlag{
+						Name:        "metrics-username",
+						Usage:       "username to require basic auth for metrics",
+						Destination: &metricsConfig.Username,
+						EnvVars:     []string{"METRICS_USERNAME"},
+					},
+					&cli.StringFlag{
+						Name:        "metrics-password",
+						Usage:       "password to require basic auth for metrics",
+						Destination: &metricsConfig.Password,
+						EnvVars:     []string{"METRICS_PASSWORD"},
+					},
+					&cli.StringFlag{
+						Name:        "metrics-token",
+						Usage:       "bearer token to require for metrics",
+						Destination: &metricsConfig.BearerToken,
+						EnvVars:     []string{"METRICS_TOKEN"},
+					},
+					&cli.Float64Flag{
+						Name:        "client-rate-limit",
+						Usage:       "max requests per minute for each client IP on each route. Set to 0 to disable",
+						Value:       clientRateLimit.PerMinute,
+						Destination: &clientRateLimit.PerMinute,
+					},
+					&cli.IntFlag{
+						Name:        "client-rate-limit-burst",
+						Usage:       "max requests each client IP can make at once on each route",
+						Value:       clientRateLimit.Burst,
+						Destination: &clientRateLimit.Burst,
+					},
+					&cli.BoolFlag{
+						Name:        "trust-proxy-headers",
+						Usage:       "use the Fly-Client-IP header or the last X-Forwarded-For address to get the client IP",
+						Destination: &trustProxyHeaders,
+						EnvVars:     []string{"TRUST_PROXY_HEADERS"},
+					},
+					&cli.StringFlag{
+						Name:        "lists-dir",
+						Usage:       "directory to save shared favorite lists in. Lists are only kept in memory if not set. The oldest lists are removed after 10000 are saved",
+						Destination: &listsDir,
+						EnvVars:     []string{"LISTS_DIR"},
+					},
+					&cli.StringFlag{
+						Name:        "changes-file",
+						Usage:       "JSON file to save detected schedule changes in. Changes are only kept in memory if not set",
+						Destination: &changesFile,
+						EnvVars:     []string{"CHANGES_FILE"},
+					},
+					&cli.MultiStringFlag{
+						Target: &cli.StringSliceFlag{
+							Name:    "stocking-report",
+							Usage:   "file or URL of an AZGFD weekly stocking report CSV or XLSX to show if scheduled stockings happened. Reports are loaded again every hour",
+							EnvVars: []string{"STOCKING_REPORTS"},
+						},
+						Destination: &stockingReports,
+					},
+					&cli.StringFlag{
+						Name:        "schedule-dir",
+						Usage:       "directory of downloaded CSV or XLSX copies of the spreadsheets to use instead of Google Sheets",
+						Destination: &scheduleDir,
+						EnvVars:     []string{"SCHEDULE_DIR"},
+					},
+					&cli.DurationFlag{
+						Name:        "read-timeout",
+						Usage:       "max duration for reading an entire request",
+						Value:       serverTimeouts.Read,
+						Destination: &serverTimeouts.Read,
+					},
+					&cli.DurationFlag{
+						Name:        "read-header-timeout",
+						Usage:       "max duration for reading request headers",
+						Value:       serverTimeouts.ReadHeader,
+						Destination: &serverTimeouts.ReadHeader,
+					},
+					&cli.DurationFlag{
+						Name:        "write-timeout",
+						Usage:       "max duration before timing out writes of the response",
+						Value:       serverTimeouts.Write,
+						Destination: &serverTimeouts.Write,
+					},
+					&cli.DurationFlag{
+						Name:        "idle-timeout",
+						Usage:       "max duration to wait for the next request on a keep-alive connection",
+						Value:       serverTimeouts.Idle,
+						Destination: &serverTimeouts.Idle,
+					},
+					&cli.DurationFlag{
+						Name:        "shutdown-timeout",
+						Usage:       "max duration to wait for in-flight requests when shutting down",
+						Value:       serverTimeouts.Shutdown,
+						Destination: &serverTimeouts.Shutdown,
+					},
 				},
 				Description: "run an HTTP server that responds with the AZ GFD fish stocking schedule",
 				Action: func(ctx *cli.Context) error {
-					rt := setupCacheControl(cacheMaxAge, cacheDir)
-					if debug {
-						rt = transport.Log(rt)
-					}
-					srv, err := azstocker.NewService(apiKey, rt)
-					if err != nil {
-						return fmt.Errorf("error creating Sheets service: %w", err)
+					var srv *sheets.Service
+					if scheduleDir == "" {
+						rt := setupCacheControl(cacheMaxAge, cacheDir, transport.NewRateLimit(rateLimit, nil))
+						if debug {
+							rt = transport.Log(rt)
+						}
+						var err error
+						srv, err = azstocker.NewService(apiKey, rt)
+						if err != nil {
+							return fmt.Errorf("error creating Sheets service: %w", err)
+						}
 					}
 
-					opts := []server.Option{}
+					opts := []server.Option{
+						server.WithReadyMaxAge(cacheMaxAge),
+						server.WithTimeouts(serverTimeouts),
+						server.WithMetrics(metricsConfig),
+						server.WithClientRateLimit(clientRateLimit),
+						server.WithTrustProxyHeaders(trustProxyHeaders),
+						server.WithListsDir(listsDir),
+						server.WithChangesFile(changesFile),
+						server.WithStockingReports(stockingReports...),
+						server.WithScheduleDir(scheduleDir),
+					}
 					if pushoverAppToken != "" && pushoverRecipientToken != "" {
 						opts = append(opts, server.WithPushoverClient(pushoverAppToken, pushoverRecipientToken))
 					}
 
-					return server.RunServer(addr, srv, urlBase, opts...)
+					runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+					defer stop()
+
+					return server.RunServer(runCtx, addr, srv, urlBase, opts...)
 				},
 			},
 		},
@@ -155,11 +610,11 @@ func main() {
 	}
 }
 
-func setupCacheControl(maxAge time.Duration, dir string) http.RoundTripper {
+func setupCacheControl(maxAge time.Duration, dir string, next http.RoundTripper) http.RoundTripper {
 	switch dir {
 	case "":
-		return transport.NewCacheControl(maxAge, nil)
+		return transport.NewCacheControl(maxAge, next)
 	default:
-		return transport.NewDiskCacheControl(dir, maxAge, nil)
+		return transport.NewDiskCacheControl(dir, maxAge, next)
 	}
 }
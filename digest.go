@@ -0,0 +1,197 @@
+package azstocker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// digestSpecies is the order of species in a WeekDigest. Other fish are after these
+var digestSpecies = []Fish{Trout, Catfish, UnknownFish}
+
+// WeekDigest shows every water that is scheduled to be stocked in a single week across Programs
+type WeekDigest struct {
+	// Start is Monday at midnight in Arizona
+	Start time.Time
+	// End is the Sunday at the end of the week
+	End     time.Time
+	Species []SpeciesStockings
+}
+
+// SpeciesStockings are the stockings for one Fish, grouped by region
+type SpeciesStockings struct {
+	Fish    Fish
+	Regions []RegionStockings
+}
+
+// RegionStockings are the stockings in one region. The Region is empty for waters that are not in a
+// section of the schedule
+type RegionStockings struct {
+	Region    string
+	Stockings []Stocking
+}
+
+// Stocking is a single scheduled stocking at a water
+type Stocking struct {
+	Program   Program
+	WaterName string
+	Week      Week
+}
+
+// WeekOf returns the Monday and Sunday of the week that includes the date in Arizona
+func WeekOf(date time.Time) (time.Time, time.Time) {
+	date = dateOnly(date)
+	// time.Sunday is 0, so it is moved to the end of the week
+	offset := (int(date.Weekday()) + 6) % 7
+	start := date.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 6)
+}
+
+// NewWeekDigest creates a WeekDigest for the week that includes the date using data that was already
+// fetched for each Program. It includes stocking ranges that overlap the week, even if they start before it
+func NewWeekDigest(date time.Time, data map[Program]StockingData) WeekDigest {
+	start, end := WeekOf(date)
+	digest := WeekDigest{Start: start, End: end}
+
+	bySpecies := map[Fish]map[string][]Stocking{}
+	for program, stockingData := range data {
+		for _, calendar := range stockingData.Filter(Between(start, end)) {
+			for _, week := range calendar.Data {
+				if bySpecies[week.Stock] == nil {
+					bySpecies[week.Stock] = map[string][]Stocking{}
+				}
+				bySpecies[week.Stock][calendar.Region] = append(bySpecies[week.Stock][calendar.Region], Stocking{
+					Program:   program,
+					WaterName: calendar.WaterName,
+					Week:      week,
+				})
+			}
+		}
+	}
+
+	for fish, regions := range bySpecies {
+		species := SpeciesStockings{Fish: fish}
+		for region, stockings := range regions {
+			slices.SortFunc(stockings, func(a, b Stocking) int {
+				if c := strings.Compare(a.WaterName, b.WaterName); c != 0 {
+					return c
+				}
+				return strings.Compare(string(a.Program), string(b.Program))
+			})
+			species.Regions = append(species.Regions, RegionStockings{Region: region, Stockings: stockings})
+		}
+		slices.SortFunc(species.Regions, compareRegions)
+		digest.Species = append(digest.Species, species)
+	}
+	slices.SortFunc(digest.Species, func(a, b SpeciesStockings) int {
+		return compareSpecies(a.Fish, b.Fish)
+	})
+
+	return digest
+}
+
+// GetWeekDigest gets stocking data for every Program and creates a WeekDigest for the week that includes
+// the date. Each Program uses the Season that includes the week, and Programs without one are skipped
+func GetWeekDigest(ctx context.Context, srv *sheets.Service, date time.Time, opts ...Option) (WeekDigest, error) {
+	start, end := WeekOf(date)
+
+	data := map[Program]StockingData{}
+	for _, program := range Programs {
+		seasons, err := Seasons(ctx, srv, program, opts...)
+		if err != nil {
+			return WeekDigest{}, fmt.Errorf("error getting %s seasons: %w", program, err)
+		}
+
+		season, ok := CurrentSeason(seasons, start)
+		if !ok || !(season.Contains(start) || season.Contains(end)) {
+			continue
+		}
+
+		stockingData, err := GetContext(ctx, srv, program, nil, append(opts, WithSeason(season))...)
+		if err != nil {
+			return WeekDigest{}, fmt.Errorf("error getting %s data: %w", program, err)
+		}
+		data[program] = stockingData
+	}
+
+	return NewWeekDigest(date, data), nil
+}
+
+// Len is the total number of stockings in the WeekDigest
+func (d WeekDigest) Len() int {
+	total := 0
+	for _, species := range d.Species {
+		for _, region := range species.Regions {
+			total += len(region.Stockings)
+		}
+	}
+	return total
+}
+
+// RangeIn describes the WeekDigest's dates in the specified Language, like "October 14 – October 20, 2024"
+func (d WeekDigest) RangeIn(lang Language) string {
+	return lang.t("digest.range", lang.month(d.Start.Month()), d.Start.Day(), lang.month(d.End.Month()), d.End.Day(), d.End.Year())
+}
+
+// String formats the WeekDigest with a section for each species and region
+func (d WeekDigest) String() string {
+	return d.StringIn(English)
+}
+
+// StringIn is String in the specified Language
+func (d WeekDigest) StringIn(lang Language) string {
+	var sb strings.Builder
+	sb.WriteString(d.RangeIn(lang))
+	if len(d.Species) == 0 {
+		sb.WriteString("\n")
+		sb.WriteString(lang.t("digest.empty"))
+	}
+
+	for _, species := range d.Species {
+		sb.WriteString("\n")
+		sb.WriteString(species.Fish.NameIn(lang))
+		for _, region := range species.Regions {
+			name := region.Region
+			if name == "" {
+				name = lang.t("digest.noRegion")
+			}
+			fmt.Fprintf(&sb, "\n  %s", name)
+			for _, stocking := range region.Stockings {
+				fmt.Fprintf(&sb, "\n    %s (%s): %s", stocking.WaterName, lang.t("program."+string(stocking.Program)), stocking.Week.DateIn(lang))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// compareRegions sorts alphabetically with waters that are not in a region at the end
+func compareRegions(a, b RegionStockings) int {
+	switch {
+	case a.Region == b.Region:
+		return 0
+	case a.Region == "":
+		return 1
+	case b.Region == "":
+		return -1
+	default:
+		return strings.Compare(a.Region, b.Region)
+	}
+}
+
+func compareSpecies(a, b Fish) int {
+	ai, bi := slices.Index(digestSpecies, a), slices.Index(digestSpecies, b)
+	if ai < 0 {
+		ai = len(digestSpecies)
+	}
+	if bi < 0 {
+		bi = len(digestSpecies)
+	}
+	if ai != bi {
+		return ai - bi
+	}
+	return strings.Compare(string(a), string(b))
+}
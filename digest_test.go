@@ -0,0 +1,169 @@
+package azstocker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		date  time.Time
+		start time.Time
+	}{
+		{"Monday", time.Date(2024, time.October, 14, 8, 0, 0, 0, azTime), time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime)},
+		{"Wednesday", time.Date(2024, time.October, 16, 8, 0, 0, 0, azTime), time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime)},
+		{"Sunday", time.Date(2024, time.October, 20, 23, 0, 0, 0, azTime), time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime)},
+		{"MonthEnd", time.Date(2024, time.November, 1, 0, 0, 0, 0, azTime), time.Date(2024, time.October, 28, 0, 0, 0, 0, azTime)},
+		// Monday at 2AM UTC is still Sunday in Arizona
+		{"UTC", time.Date(2024, time.October, 21, 2, 0, 0, 0, time.UTC), time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := WeekOf(tt.date)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.start.AddDate(0, 0, 6), end)
+		})
+	}
+}
+
+func TestNewWeekDigest(t *testing.T) {
+	data := map[Program]StockingData{
+		CFProgram: {
+			{
+				WaterName: "Tempe - Kiwanis Lake",
+				Data: []Week{
+					{Year: 2024, Month: time.October, Day: 7, Stock: Catfish},
+					{Year: 2024, Month: time.October, Day: 14, Stock: Catfish},
+				},
+			},
+			{
+				WaterName: "Payson - Green Valley Lakes",
+				Region:    "PAYSON AREA WATERS",
+				Data: []Week{
+					{Year: 2024, Month: time.October, Day: 14, Stock: Trout},
+				},
+			},
+			{
+				WaterName: "Tempe - Tempe Town Lake",
+				Data: []Week{
+					{Year: 2024, Month: time.October, Day: 14, Stock: NoneFish},
+				},
+			},
+		},
+		WinterProgram: {
+			{
+				WaterName: "LOWER SALT RIVER",
+				Region:    "Phoenix Area",
+				Data: []Week{
+					{Year: 2024, Month: time.October, Day: 14, Stock: Trout},
+					{Year: 2024, Month: time.October, Day: 21, Stock: Trout},
+				},
+			},
+			{
+				WaterName: "Dankworth Pond",
+				Region:    "Safford Area",
+				Data: []Week{
+					{Year: 2024, Month: time.October, Day: 14, Stock: UnknownFish},
+				},
+			},
+		},
+	}
+
+	digest := NewWeekDigest(time.Date(2024, time.October, 17, 0, 0, 0, 0, azTime), data)
+	assert.Equal(t, WeekDigest{
+		Start: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime),
+		End:   time.Date(2024, time.October, 20, 0, 0, 0, 0, azTime),
+		Species: []SpeciesStockings{
+			{
+				Fish: Trout,
+				Regions: []RegionStockings{
+					{Region: "PAYSON AREA WATERS", Stockings: []Stocking{
+						{Program: CFProgram, WaterName: "Payson - Green Valley Lakes", Week: Week{Year: 2024, Month: time.October, Day: 14, Stock: Trout}},
+					}},
+					{Region: "Phoenix Area", Stockings: []Stocking{
+						{Program: WinterProgram, WaterName: "LOWER SALT RIVER", Week: Week{Year: 2024, Month: time.October, Day: 14, Stock: Trout}},
+					}},
+				},
+			},
+			{
+				Fish: Catfish,
+				Regions: []RegionStockings{
+					{Region: "", Stockings: []Stocking{
+						{Program: CFProgram, WaterName: "Tempe - Kiwanis Lake", Week: Week{Year: 2024, Month: time.October, Day: 14, Stock: Catfish}},
+					}},
+				},
+			},
+			{
+				Fish: UnknownFish,
+				Regions: []RegionStockings{
+					{Region: "Safford Area", Stockings: []Stocking{
+						{Program: WinterProgram, WaterName: "Dankworth Pond", Week: Week{Year: 2024, Month: time.October, Day: 14, Stock: UnknownFish}},
+					}},
+				},
+			},
+		},
+	}, digest)
+	assert.Equal(t, 4, digest.Len())
+	assert.Equal(t, `October 14 – October 20, 2024
+Trout
+  PAYSON AREA WATERS
+    Payson - Green Valley Lakes (Community Fishing Program): 2024 October 14
+  Phoenix Area
+    LOWER SALT RIVER (Winter): 2024 October 14
+Catfish
+  Other Waters
+    Tempe - Kiwanis Lake (Community Fishing Program): 2024 October 14
+Unknown
+  Safford Area
+    Dankworth Pond (Winter): 2024 October 14`, digest.String())
+	assert.Contains(t, digest.StringIn(Spanish), "14 de octubre – 20 de octubre de 2024")
+
+	t.Run("RangeStartsInPreviousWeek", func(t *testing.T) {
+		week := Week{Year: 2024, Month: time.October, Day: 10, EndMonth: time.October, EndDay: 16, Stock: Trout}
+		digest := NewWeekDigest(time.Date(2024, time.October, 17, 0, 0, 0, 0, azTime), map[Program]StockingData{
+			WinterProgram: {{WaterName: "GOLDWATER LAKE", Region: "Prescott Area", Data: []Week{week}}},
+		})
+		assert.Equal(t, []SpeciesStockings{{
+			Fish: Trout,
+			Regions: []RegionStockings{{Region: "Prescott Area", Stockings: []Stocking{
+				{Program: WinterProgram, WaterName: "GOLDWATER LAKE", Week: week},
+			}}},
+		}}, digest.Species)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		digest := NewWeekDigest(time.Date(2024, time.December, 2, 0, 0, 0, 0, azTime), data)
+		assert.Empty(t, digest.Species)
+		assert.Zero(t, digest.Len())
+		assert.Equal(t, "December 2 – December 8, 2024\nNo stockings are scheduled for this week.", digest.String())
+	})
+}
+
+func TestGetWeekDigest(t *testing.T) {
+	srv := newTestSheetsService(t, fakeWinterSheet([]any{"OCTOBER"}, []any{"LOWER SALT RIVER", "X"}))
+
+	digest, err := GetWeekDigest(context.Background(), srv, time.Date(2024, time.October, 2, 0, 0, 0, 0, azTime))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, digest.Len())
+	assert.Equal(t, WinterProgram, digest.Species[0].Regions[0].Stockings[0].Program)
+
+	t.Run("OutOfSeason", func(t *testing.T) {
+		digest, err := GetWeekDigest(context.Background(), srv, time.Date(2025, time.June, 2, 0, 0, 0, 0, azTime))
+		assert.NoError(t, err)
+		assert.Zero(t, digest.Len())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		_, err := GetWeekDigest(context.Background(), srv, time.Date(2024, time.October, 2, 0, 0, 0, 0, azTime))
+		assert.ErrorIs(t, err, ErrUpstream)
+	})
+}
@@ -0,0 +1,79 @@
+package azstocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/calvinmclean/azstocker/internal/transport"
+	"google.golang.org/api/googleapi"
+)
+
+// These errors are returned when getting stocking data and can be checked with errors.Is
+var (
+	// ErrUnknownProgram is returned for a Program that does not have a stocking schedule
+	ErrUnknownProgram = errors.New("unknown program")
+	// ErrNoMatchingWaters is returned when waters are requested, but none of them are in the schedule
+	ErrNoMatchingWaters = errors.New("no matching waters")
+	// ErrSheetNotFound is returned when the Program's spreadsheet does not have a sheet for the Season
+	ErrSheetNotFound = errors.New("sheet not found")
+	// ErrUpstream is returned when a request to the Google Sheets API fails
+	ErrUpstream = errors.New("error from Google Sheets API")
+	// ErrQuotaExceeded is returned when the Google Sheets API rejects a request because the quota or rate
+	// limit was exceeded, or when the client's own rate limit was reached. These errors also match ErrUpstream
+	ErrQuotaExceeded = errors.New("quota exceeded for Google Sheets API")
+	// ErrLayoutMismatch is returned when a sheet does not have the expected rows and columns, which usually
+	// means the spreadsheet's format changed
+	ErrLayoutMismatch = errors.New("sheet layout does not match")
+)
+
+// UpstreamError is a failed request to the Google Sheets API. It matches ErrUpstream, and it also matches
+// ErrQuotaExceeded if the API responded with Too Many Requests or the local rate limit rejected the request
+type UpstreamError struct {
+	// StatusCode is the API's response code. It is zero if the request failed without a response
+	StatusCode int
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrUpstream, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	switch target {
+	case ErrUpstream:
+		return true
+	case ErrQuotaExceeded:
+		return e.StatusCode == http.StatusTooManyRequests || errors.Is(e.Err, transport.ErrQuotaExhausted)
+	default:
+		return false
+	}
+}
+
+// classifyError wraps an error from the Sheets API so it can be checked with errors.Is. The API responds
+// with a Bad Request when a range's sheet name doesn't exist
+func classifyError(err error) error {
+	// the local rate limit can wrap the deadline from waiting for a token, so it is checked first
+	if errors.Is(err, transport.ErrQuotaExhausted) {
+		return &UpstreamError{Err: err}
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return &UpstreamError{Err: err}
+	}
+
+	if apiErr.Code == http.StatusBadRequest && strings.Contains(apiErr.Message, "Unable to parse range") {
+		return fmt.Errorf("%w: %w", ErrSheetNotFound, err)
+	}
+	return &UpstreamError{StatusCode: apiErr.Code, Err: err}
+}
@@ -0,0 +1,174 @@
+package azstocker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/calvinmclean/azstocker/internal/transport"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// newTestSheetsService creates a Service that sends requests to the handler instead of the Sheets API
+func newTestSheetsService(t *testing.T, handler http.HandlerFunc) *sheets.Service {
+	t.Helper()
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+	return srv
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		status      int
+		body        string
+		expectedErr error
+		unexpected  error
+	}{
+		{
+			"UpstreamUnavailable",
+			nil,
+			http.StatusServiceUnavailable,
+			`{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}`,
+			ErrUpstream,
+			ErrSheetNotFound,
+		},
+		{
+			"MissingSeasonSheet",
+			[]Option{WithSeason(Season{Program: WinterProgram, Name: "2019-20 Winter"})},
+			http.StatusBadRequest,
+			`{"error":{"code":400,"message":"Unable to parse range: '2019-20 Winter'!B4:5","status":"INVALID_ARGUMENT"}}`,
+			ErrSheetNotFound,
+			ErrUpstream,
+		},
+		{
+			"NoSeasons",
+			nil,
+			http.StatusOK,
+			`{"sheets":[{"properties":{"title":"Notes"}}]}`,
+			ErrSheetNotFound,
+			ErrUpstream,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			_, err := Get(srv, WinterProgram, nil, tt.opts...)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.NotErrorIs(t, err, tt.unexpected)
+			assert.Equal(t, 1, requests, "failed requests should not be retried with a different sheet")
+		})
+	}
+
+	t.Run("QuotaExceeded", func(t *testing.T) {
+		srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`))
+		})
+
+		_, err := Get(srv, WinterProgram, nil)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.ErrorIs(t, err, ErrUpstream)
+
+		var upstreamErr *UpstreamError
+		assert.ErrorAs(t, err, &upstreamErr)
+		assert.Equal(t, http.StatusTooManyRequests, upstreamErr.StatusCode)
+	})
+
+	t.Run("LocalQuotaExhausted", func(t *testing.T) {
+		ts := httptest.NewServer(fakeWinterSheet([]any{"OCTOBER"}, []any{"LOWER SALT RIVER", "X"}))
+		defer ts.Close()
+
+		// the first request gets the metadata and the next one is rejected by the rate limit
+		client := &http.Client{Transport: transport.NewRateLimit(transport.RateLimitConfig{PerMinute: 1, Burst: 1}, ts.Client().Transport)}
+		srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(client))
+		assert.NoError(t, err)
+
+		_, err = Get(srv, WinterProgram, nil)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.ErrorIs(t, err, ErrUpstream)
+		assert.ErrorIs(t, err, transport.ErrQuotaExhausted)
+	})
+
+	t.Run("LocalQuotaWait", func(t *testing.T) {
+		err := classifyError(fmt.Errorf("%w: %w", transport.ErrQuotaExhausted, context.DeadlineExceeded))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("UnknownProgram", func(t *testing.T) {
+		_, err := Get(nil, Program("fall"), nil)
+		assert.ErrorIs(t, err, ErrUnknownProgram)
+
+		_, err = ParseProgram("fall")
+		assert.ErrorIs(t, err, ErrUnknownProgram)
+	})
+
+	t.Run("NoMatchingWaters", func(t *testing.T) {
+		srv := newTestSheetsService(t, fakeWinterSheet([]any{"OCTOBER"}, []any{"LOWER SALT RIVER", "X"}))
+
+		_, err := Get(srv, WinterProgram, []string{"Lynx Lake"})
+		assert.ErrorIs(t, err, ErrNoMatchingWaters)
+		assert.EqualError(t, err, "no matching waters: Lynx Lake")
+
+		stockData, err := Get(srv, WinterProgram, []string{"lower salt river", "Lynx Lake"})
+		assert.NoError(t, err)
+		assert.Len(t, stockData, 1)
+	})
+
+	t.Run("LayoutMismatch", func(t *testing.T) {
+		srv := newTestSheetsService(t, fakeWinterSheet([]any{"NOT A MONTH"}, []any{"LOWER SALT RIVER", "X"}))
+
+		_, err := Get(srv, WinterProgram, nil)
+		assert.ErrorIs(t, err, ErrLayoutMismatch)
+		assert.NotErrorIs(t, err, ErrUpstream)
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := GetContext(ctx, srv, WinterProgram, nil)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.NotErrorIs(t, err, ErrUpstream)
+	})
+}
+
+// fakeWinterSheet responds with a 2024-25 Winter sheet that has the month header and a single water row
+func fakeWinterSheet(months, row []any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"):
+			resp = sheets.ValueRange{Values: [][]any{months, {"1"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{row}}
+		default:
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}}}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,89 @@
+package azstocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Record is a single stocking in the long format used for exports. There is one Record for each water and
+// stocked Week
+type Record struct {
+	Program Program
+	Season  string
+	Water   string
+	// Date is the first day of the stocking Week
+	Date    time.Time
+	Species Fish
+	// Status is only set if the StockingData was reconciled with reports
+	Status Status
+}
+
+// NewRecords converts a Season's StockingData to Records. Weeks without a stocking are left out
+func NewRecords(season Season, data StockingData) []Record {
+	result := []Record{}
+	for _, calendar := range data {
+		for _, week := range calendar.Data {
+			if week.Stock == NoneFish {
+				continue
+			}
+			result = append(result, Record{
+				Program: season.Program,
+				Season:  season.Name,
+				Water:   calendar.WaterName,
+				Date:    week.Time(),
+				Species: week.Stock,
+				Status:  week.Status,
+			})
+		}
+	}
+	return result
+}
+
+// SkippedSeason is an archived Season that GetRecords left out because its sheet could not be read
+type SkippedSeason struct {
+	Season Season
+	Err    error
+}
+
+// GetRecords gets Records for the Programs, or all Programs if none are provided. By default, only the
+// current Season is used. If archived is true, every Season in the spreadsheet is used. Archived Seasons
+// with a different layout are skipped since older sheets might not be formatted the same way
+func GetRecords(ctx context.Context, srv *sheets.Service, programs []Program, archived bool, opts ...Option) ([]Record, []SkippedSeason, error) {
+	if len(programs) == 0 {
+		programs = Programs
+	}
+
+	result := []Record{}
+	skipped := []SkippedSeason{}
+	for _, program := range programs {
+		seasons, err := Seasons(ctx, srv, program, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting %s seasons: %w", program, err)
+		}
+
+		current, ok := CurrentSeason(seasons, Now())
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: no seasons found for program %q", ErrSheetNotFound, program)
+		}
+		if !archived {
+			seasons = []Season{current}
+		}
+
+		for _, season := range seasons {
+			data, err := GetContext(ctx, srv, program, nil, append(opts, WithSeason(season))...)
+			if archived && season != current && errors.Is(err, ErrLayoutMismatch) {
+				skipped = append(skipped, SkippedSeason{season, err})
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("error getting %s data for %q: %w", program, season.Name, err)
+			}
+			result = append(result, NewRecords(season, data)...)
+		}
+	}
+	return result, skipped, nil
+}
@@ -0,0 +1,65 @@
+package azstocker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecords(t *testing.T) {
+	season := Season{Program: WinterProgram, Name: "2024-25 Winter"}
+
+	records := NewRecords(season, expectedFileData)
+	assert.Equal(t, []Record{
+		{Program: WinterProgram, Season: "2024-25 Winter", Water: "Lynx Lake", Date: time.Date(2024, time.October, 7, 0, 0, 0, 0, azTime), Species: Trout},
+		{Program: WinterProgram, Season: "2024-25 Winter", Water: "Lynx Lake", Date: time.Date(2024, time.October, 21, 0, 0, 0, 0, azTime), Species: Catfish},
+		{Program: WinterProgram, Season: "2024-25 Winter", Water: "Goldwater Lake", Date: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), Species: Trout},
+		{Program: WinterProgram, Season: "2024-25 Winter", Water: "Goldwater Lake", Date: time.Date(2024, time.November, 4, 0, 0, 0, 0, azTime), Species: Trout},
+	}, records)
+}
+
+func TestGetRecords(t *testing.T) {
+	getNow = func() time.Time { return time.Date(2024, time.November, 1, 12, 0, 0, 0, azTime) }
+	defer func() { getNow = time.Now }()
+
+	dir := t.TempDir()
+	writeTestCSV(t, filepath.Join(dir, "2024-25 Winter.csv"))
+	writeTestCSV(t, filepath.Join(dir, "2023-24 Winter.csv"))
+	// an older sheet with a different layout is skipped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2022-23 Winter.csv"), []byte("Lynx Lake,X"), 0o600))
+
+	spreadsheet, err := OpenSpreadsheetDir(dir)
+	require.NoError(t, err)
+
+	t.Run("Current", func(t *testing.T) {
+		records, skipped, err := GetRecords(context.Background(), nil, []Program{WinterProgram}, false, WithSpreadsheet(spreadsheet))
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+		assert.Len(t, records, 4)
+		for _, record := range records {
+			assert.Equal(t, "2024-25 Winter", record.Season)
+		}
+	})
+
+	t.Run("Archived", func(t *testing.T) {
+		records, skipped, err := GetRecords(context.Background(), nil, []Program{WinterProgram}, true, WithSpreadsheet(spreadsheet))
+		require.NoError(t, err)
+		require.Len(t, skipped, 1)
+		assert.Equal(t, "2022-23 Winter", skipped[0].Season.Name)
+		assert.ErrorIs(t, skipped[0].Err, ErrLayoutMismatch)
+		require.Len(t, records, 8)
+		assert.Equal(t, "2023-24 Winter", records[0].Season)
+		assert.Equal(t, time.Date(2023, time.October, 7, 0, 0, 0, 0, azTime), records[0].Date)
+		assert.Equal(t, "2024-25 Winter", records[4].Season)
+	})
+
+	t.Run("MissingProgram", func(t *testing.T) {
+		_, _, err := GetRecords(context.Background(), nil, nil, false, WithSpreadsheet(spreadsheet))
+		assert.ErrorIs(t, err, ErrSheetNotFound)
+	})
+}
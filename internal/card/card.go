@@ -0,0 +1,165 @@
+// Package card renders social preview images. It only uses pure Go so it does not need a browser or any
+// system fonts
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// Width and Height are the recommended size for Open Graph images
+	Width  = 1200
+	Height = 630
+
+	padding      = 64
+	headerHeight = 250
+	ellipsis     = "…"
+)
+
+var (
+	headerColor = color.RGBA{0x1e, 0x87, 0xf0, 0xff}
+	bodyColor   = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	titleColor  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	labelColor  = color.RGBA{0x66, 0x66, 0x66, 0xff}
+	textColor   = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	footerColor = color.RGBA{0x1e, 0x87, 0xf0, 0xff}
+)
+
+// Card is the content of a preview image
+type Card struct {
+	Title    string
+	Subtitle string
+	Lines    []Line
+	Footer   string
+}
+
+// Line is a labeled value in the body of the Card
+type Line struct {
+	Label string
+	Value string
+}
+
+type faces struct {
+	title, subtitle, label, value, footer font.Face
+}
+
+type fonts struct {
+	regular, bold *opentype.Font
+}
+
+// loadFonts parses the fonts once. Faces are created for each image since they are not safe for
+// concurrent use
+var loadFonts = sync.OnceValues(func() (fonts, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return fonts{}, fmt.Errorf("error parsing regular font: %w", err)
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return fonts{}, fmt.Errorf("error parsing bold font: %w", err)
+	}
+	return fonts{regular, bold}, nil
+})
+
+func newFaces() (faces, error) {
+	f, err := loadFonts()
+	if err != nil {
+		return faces{}, err
+	}
+
+	var result faces
+	for _, face := range []struct {
+		dest *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&result.title, f.bold, 64},
+		{&result.subtitle, f.regular, 34},
+		{&result.label, f.regular, 30},
+		{&result.value, f.bold, 44},
+		{&result.footer, f.bold, 30},
+	} {
+		*face.dest, err = opentype.NewFace(face.font, &opentype.FaceOptions{Size: face.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return faces{}, fmt.Errorf("error creating font face: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Render draws the Card and encodes it as a PNG
+func Render(w io.Writer, c Card) error {
+	img, err := Draw(c)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Draw draws the Card. Text that doesn't fit is truncated
+func Draw(c Card) (*image.RGBA, error) {
+	f, err := newFaces()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bodyColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, headerHeight), image.NewUniform(headerColor), image.Point{}, draw.Src)
+
+	maxWidth := Width - 2*padding
+	drawText(img, f.title, titleColor, c.Title, padding, 120, maxWidth)
+	drawText(img, f.subtitle, titleColor, c.Subtitle, padding, 190, maxWidth)
+
+	y := headerHeight + 70
+	for _, line := range c.Lines {
+		drawText(img, f.label, labelColor, line.Label, padding, y, maxWidth)
+		drawText(img, f.value, textColor, line.Value, padding, y+52, maxWidth)
+		y += 130
+	}
+
+	drawText(img, f.footer, footerColor, c.Footer, padding, Height-40, maxWidth)
+
+	return img, nil
+}
+
+// drawText draws a single line of text with its baseline at y
+func drawText(img draw.Image, face font.Face, c color.Color, text string, x, y, maxWidth int) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(truncate(face, text, maxWidth))
+}
+
+// truncate shortens the text and adds an ellipsis if it is wider than maxWidth
+func truncate(face font.Face, text string, maxWidth int) string {
+	limit := fixed.I(maxWidth)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + ellipsis
+		if font.MeasureString(face, candidate) <= limit {
+			return candidate
+		}
+	}
+	return ellipsis
+}
@@ -0,0 +1,40 @@
+package card
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, Card{
+		Title:    "Queen Creek - Mansel Carter Oasis Lake",
+		Subtitle: "Community Fishing Program",
+		Lines: []Line{
+			{Label: "Last stocked · 2024 October 21", Value: "Catfish · 1 week ago"},
+			{Label: "Next stocking · 2024 November 4", Value: "Catfish · 2 days from now"},
+		},
+		Footer: "azstocker.com",
+	})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, Width, img.Bounds().Dx())
+	assert.Equal(t, Height, img.Bounds().Dy())
+}
+
+func TestTruncate(t *testing.T) {
+	f, err := newFaces()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Short", truncate(f.title, "Short", 500))
+
+	truncated := truncate(f.title, strings.Repeat("Long Water Name ", 10), 500)
+	assert.True(t, strings.HasSuffix(truncated, ellipsis))
+	assert.Less(t, len(truncated), 160)
+}
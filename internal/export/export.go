@@ -0,0 +1,212 @@
+// Package export writes stocking Records as CSV, Parquet, or SQLite for analysis in other tools
+package export
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/parquet-go/parquet-go"
+
+	// register the pure Go SQLite driver so the binary can be built without cgo
+	_ "modernc.org/sqlite"
+)
+
+// Format is a file format for exports
+type Format string
+
+const (
+	CSV     Format = "csv"
+	Parquet Format = "parquet"
+	SQLite  Format = "sqlite"
+)
+
+// columns are the names of the fields in every Format
+var columns = []string{"program", "season", "water", "date", "species", "status"}
+
+// ParseFormat parses a Format name like "csv"
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(strings.ToLower(format)); f {
+	case CSV, Parquet, SQLite:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// FormatFromPath chooses the Format using the file extension. It is CSV if the extension is not recognized
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".parquet":
+		return Parquet
+	case ".sqlite", ".sqlite3", ".db":
+		return SQLite
+	default:
+		return CSV
+	}
+}
+
+// WriteCSV writes the Records as CSV with a header row. Dates are like 2024-10-21
+func WriteCSV(w io.Writer, records []azstocker.Record) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(columns)
+	if err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+	for _, record := range records {
+		err = writer.Write([]string{
+			string(record.Program),
+			record.Season,
+			record.Water,
+			record.Date.Format(time.DateOnly),
+			string(record.Species),
+			string(record.Status),
+		})
+		if err != nil {
+			return fmt.Errorf("error writing record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parquetRow is a Record in a Parquet file. The date is the number of days since the Unix epoch, which is
+// the Parquet DATE type
+type parquetRow struct {
+	Program string `parquet:"program"`
+	Season  string `parquet:"season"`
+	Water   string `parquet:"water"`
+	Date    int32  `parquet:"date,date"`
+	Species string `parquet:"species"`
+	Status  string `parquet:"status"`
+}
+
+// WriteParquet writes the Records as a Parquet file
+func WriteParquet(w io.Writer, records []azstocker.Record) error {
+	rows := []parquetRow{}
+	for _, record := range records {
+		rows = append(rows, parquetRow{
+			Program: string(record.Program),
+			Season:  record.Season,
+			Water:   record.Water,
+			Date:    daysSinceEpoch(record.Date),
+			Species: string(record.Species),
+			Status:  string(record.Status),
+		})
+	}
+
+	writer := parquet.NewGenericWriter[parquetRow](w)
+	_, err := writer.Write(rows)
+	if err != nil {
+		return fmt.Errorf("error writing rows: %w", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("error closing writer: %w", err)
+	}
+	return nil
+}
+
+func daysSinceEpoch(t time.Time) int32 {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int32(date.Unix() / int64(24*time.Hour/time.Second))
+}
+
+// WriteSQLite writes the Records to a stockings table in a SQLite database. The file is created if it does
+// not exist, and an existing stockings table is replaced
+func WriteSQLite(path string, records []azstocker.Record) (err error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, db.Close())
+	}()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(`DROP TABLE IF EXISTS stockings;
+CREATE TABLE stockings (
+	program TEXT NOT NULL,
+	season TEXT NOT NULL,
+	water TEXT NOT NULL,
+	date TEXT NOT NULL,
+	species TEXT NOT NULL,
+	status TEXT NOT NULL
+);
+CREATE INDEX stockings_water ON stockings (program, water);`)
+	if err != nil {
+		return fmt.Errorf("error creating table: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO stockings (program, season, water, date, species, status) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("error preparing insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		_, err = stmt.Exec(string(record.Program), record.Season, record.Water, record.Date.Format(time.DateOnly), string(record.Species), string(record.Status))
+		if err != nil {
+			return fmt.Errorf("error inserting record: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes the Records to a file in the Format. If the path is empty or "-", CSV and Parquet are
+// written to stdout
+func WriteFile(path string, format Format, records []azstocker.Record) (err error) {
+	if format == SQLite {
+		if path == "" || path == "-" {
+			return errors.New("SQLite exports must be written to a file")
+		}
+		return WriteSQLite(path, records)
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "" && path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating file: %w", err)
+		}
+		// the data might not be written until the file is closed
+		defer func() {
+			closeErr := f.Close()
+			if err == nil && closeErr != nil {
+				err = fmt.Errorf("error closing file: %w", closeErr)
+			}
+		}()
+		w = f
+	}
+
+	switch format {
+	case Parquet:
+		return WriteParquet(w, records)
+	default:
+		return WriteCSV(w, records)
+	}
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRecords = []azstocker.Record{
+	{
+		Program: azstocker.CFProgram,
+		Season:  "CFP Stocking Calendar Schedule",
+		Water:   "Tempe - Kiwanis Lake",
+		Date:    time.Date(2024, time.October, 21, 0, 0, 0, 0, azstocker.Arizona),
+		Species: azstocker.Trout,
+		Status:  azstocker.Completed,
+	},
+	{
+		Program: azstocker.WinterProgram,
+		Season:  "2024-25 Winter",
+		Water:   "LYNX LAKE, PRESCOTT",
+		Date:    time.Date(2025, time.January, 6, 0, 0, 0, 0, azstocker.Arizona),
+		Species: azstocker.Catfish,
+	},
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("Parquet")
+	assert.NoError(t, err)
+	assert.Equal(t, Parquet, format)
+
+	_, err = ParseFormat("json")
+	assert.EqualError(t, err, `unknown format "json"`)
+
+	assert.Equal(t, CSV, FormatFromPath(""))
+	assert.Equal(t, CSV, FormatFromPath("stockings.csv"))
+	assert.Equal(t, Parquet, FormatFromPath("stockings.parquet"))
+	assert.Equal(t, SQLite, FormatFromPath("stockings.db"))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, testRecords)
+	require.NoError(t, err)
+
+	assert.Equal(t, `program,season,water,date,species,status
+cfp,CFP Stocking Calendar Schedule,Tempe - Kiwanis Lake,2024-10-21,Trout,completed
+winter,2024-25 Winter,"LYNX LAKE, PRESCOTT",2025-01-06,Catfish,
+`, buf.String())
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteParquet(&buf, testRecords)
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[parquetRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, []parquetRow{
+		{Program: "cfp", Season: "CFP Stocking Calendar Schedule", Water: "Tempe - Kiwanis Lake", Date: 20017, Species: "Trout", Status: "completed"},
+		{Program: "winter", Season: "2024-25 Winter", Water: "LYNX LAKE, PRESCOTT", Date: 20094, Species: "Catfish"},
+	}, rows)
+}
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stockings.db")
+
+	// writing again replaces the table instead of adding duplicate rows
+	require.NoError(t, WriteSQLite(path, testRecords))
+	require.NoError(t, WriteSQLite(path, testRecords))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT program, season, water, date, species, status FROM stockings ORDER BY date")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	result := [][]string{}
+	for rows.Next() {
+		row := make([]string, 6)
+		require.NoError(t, rows.Scan(&row[0], &row[1], &row[2], &row[3], &row[4], &row[5]))
+		result = append(result, row)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, [][]string{
+		{"cfp", "CFP Stocking Calendar Schedule", "Tempe - Kiwanis Lake", "2024-10-21", "Trout", "completed"},
+		{"winter", "2024-25 Winter", "LYNX LAKE, PRESCOTT", "2025-01-06", "Catfish", ""},
+	}, result)
+}
+
+func TestWriteFile(t *testing.T) {
+	err := WriteFile("", SQLite, testRecords)
+	assert.EqualError(t, err, "SQLite exports must be written to a file")
+
+	path := filepath.Join(t.TempDir(), "stockings.parquet")
+	require.NoError(t, WriteFile(path, Parquet, testRecords))
+
+	rows, err := parquet.ReadFile[parquetRow](path)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	t.Run("CSV", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stockings.csv")
+		require.NoError(t, WriteFile(path, CSV, testRecords))
+
+		var expected bytes.Buffer
+		require.NoError(t, WriteCSV(&expected, testRecords))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected.String(), string(data))
+	})
+}
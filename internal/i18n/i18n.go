@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/text/language"
+)
+
+// Language is a supported language for the web UI and CLI output
+type Language string
+
+const (
+	English Language = "en"
+	Spanish Language = "es"
+
+	// Default is used when no supported language is requested
+	Default = English
+)
+
+// Languages is the list of supported languages in order of preference
+var Languages = []Language{English, Spanish}
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// catalogs maps each language to its messages. They are loaded once at startup and a missing or invalid
+// catalog is a programming error
+var catalogs = func() map[Language]map[string]string {
+	result := map[Language]map[string]string{}
+	for _, lang := range Languages {
+		data, err := catalogFS.ReadFile(path.Join("catalogs", string(lang)+".json"))
+		if err != nil {
+			panic(err)
+		}
+
+		messages := map[string]string{}
+		err = json.Unmarshal(data, &messages)
+		if err != nil {
+			panic(fmt.Errorf("invalid %s catalog: %w", lang, err))
+		}
+		result[lang] = messages
+	}
+	return result
+}()
+
+var matcher = language.NewMatcher(func() []language.Tag {
+	tags := []language.Tag{}
+	for _, lang := range Languages {
+		tags = append(tags, language.Make(string(lang)))
+	}
+	return tags
+}())
+
+// Parse returns the supported Language for a language code like "es" or "es-MX"
+func Parse(code string) (Language, bool) {
+	base := strings.ToLower(strings.TrimSpace(code))
+	base, _, _ = strings.Cut(base, "-")
+	for _, lang := range Languages {
+		if string(lang) == base {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Match chooses the best supported Language from values like an explicit language code or an
+// Accept-Language header. Earlier values take priority and Default is used if nothing matches
+func Match(values ...string) Language {
+	_, i := language.MatchStrings(matcher, values...)
+	return Languages[i]
+}
+
+// T returns the message for the key formatted with args. It falls back to English and then the key
+// itself when a message is missing
+func (l Language) T(key string, args ...any) string {
+	msg, ok := catalogs[l][key]
+	if !ok {
+		msg, ok = catalogs[English][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Month returns the localized name of the month
+func (l Language) Month(m time.Month) string {
+	return l.T(fmt.Sprintf("month.%d", m))
+}
+
+// RelTime describes t relative to now, like "3 days ago" or "hace 3 días"
+func (l Language) RelTime(t, now time.Time) string {
+	if l != Spanish {
+		return humanize.RelTime(t, now, "ago", "from now")
+	}
+	return humanize.CustomRelTime(t, now, "hace", "dentro de", spanishMagnitudes)
+}
+
+var spanishMagnitudes = []humanize.RelTimeMagnitude{
+	{D: time.Second, Format: "ahora", DivBy: time.Second},
+	{D: 2 * time.Second, Format: "%s 1 segundo", DivBy: 1},
+	{D: time.Minute, Format: "%s %d segundos", DivBy: time.Second},
+	{D: 2 * time.Minute, Format: "%s 1 minuto", DivBy: 1},
+	{D: time.Hour, Format: "%s %d minutos", DivBy: time.Minute},
+	{D: 2 * time.Hour, Format: "%s 1 hora", DivBy: 1},
+	{D: humanize.Day, Format: "%s %d horas", DivBy: time.Hour},
+	{D: 2 * humanize.Day, Format: "%s 1 día", DivBy: 1},
+	{D: humanize.Week, Format: "%s %d días", DivBy: humanize.Day},
+	{D: 2 * humanize.Week, Format: "%s 1 semana", DivBy: 1},
+	{D: humanize.Month, Format: "%s %d semanas", DivBy: humanize.Week},
+	{D: 2 * humanize.Month, Format: "%s 1 mes", DivBy: 1},
+	{D: humanize.Year, Format: "%s %d meses", DivBy: humanize.Month},
+	{D: 18 * humanize.Month, Format: "%s 1 año", DivBy: 1},
+	{D: 2 * humanize.Year, Format: "%s 2 años", DivBy: 1},
+	{D: humanize.LongTime, Format: "%s %d años", DivBy: humanize.Year},
+	{D: math.MaxInt64, Format: "%s mucho tiempo", DivBy: 1},
+}
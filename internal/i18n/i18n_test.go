@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Languages {
+		for key := range catalogs[English] {
+			_, ok := catalogs[lang][key]
+			assert.Truef(t, ok, "%s catalog is missing %q", lang, key)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected Language
+	}{
+		{"Empty", nil, English},
+		{"Spanish", []string{"es-MX,es;q=0.9,en;q=0.8"}, Spanish},
+		{"English", []string{"en-US,en;q=0.9,es;q=0.5"}, English},
+		{"Unsupported", []string{"fr-FR"}, English},
+		{"ExplicitFirst", []string{"es", "en-US"}, Spanish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Match(tt.values...))
+		})
+	}
+}
+
+func TestRelTime(t *testing.T) {
+	now := time.Date(2024, time.November, 2, 13, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "3 days ago", English.RelTime(now.AddDate(0, 0, -3), now))
+	assert.Equal(t, "hace 3 días", Spanish.RelTime(now.AddDate(0, 0, -3), now))
+	assert.Equal(t, "dentro de 2 semanas", Spanish.RelTime(now.AddDate(0, 0, 15), now))
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Trucha", Spanish.T("fish.Trout"))
+	assert.Equal(t, "diciembre", Spanish.Month(time.December))
+	assert.Equal(t, "missing.key", Spanish.T("missing.key"))
+}
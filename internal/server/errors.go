@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+)
+
+const (
+	// retryAfter is the number of seconds clients should wait after the schedule is unavailable
+	retryAfter = "60"
+
+	// layoutAlertInterval is how often the same layout mismatch is reported. A changed spreadsheet fails
+	// every request until it is fixed, so this keeps it from sending a notification for each one
+	layoutAlertInterval = 24 * time.Hour
+)
+
+// errorStatus chooses the response code for an error from getting stocking data. Only unexpected errors
+// use a 500 so they are reported by errorHandler. Layout mismatches are reported once by
+// reportLayoutMismatch instead
+func errorStatus(err error) int {
+	var upstreamErr *azstocker.UpstreamError
+	switch {
+	case errors.Is(err, azstocker.ErrUnknownProgram):
+		return http.StatusBadRequest
+	case errors.Is(err, azstocker.ErrNoMatchingWaters), errors.Is(err, azstocker.ErrSheetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, azstocker.ErrLayoutMismatch):
+		return http.StatusBadGateway
+	// an invalid API key is a configuration problem that needs to be fixed
+	case errors.As(err, &upstreamErr) && (upstreamErr.StatusCode == 0 ||
+		upstreamErr.StatusCode == http.StatusTooManyRequests ||
+		upstreamErr.StatusCode >= http.StatusInternalServerError):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorMessage is a localized explanation of the error for users
+func errorMessage(lang i18n.Language, err error, waters []string) string {
+	switch {
+	case errors.Is(err, azstocker.ErrUnknownProgram):
+		return lang.T("error.badRequest")
+	case errors.Is(err, azstocker.ErrNoMatchingWaters):
+		return lang.T("error.noWaters", strings.Join(waters, ", "))
+	case errors.Is(err, azstocker.ErrSheetNotFound):
+		return lang.T("error.noSchedule")
+	default:
+		return lang.T("error.unavailable")
+	}
+}
+
+// alertTracker remembers when each alert was sent so it is only sent once per interval
+type alertTracker struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// shouldSend checks if the alert was not sent in the interval. If it wasn't, it is recorded as sent
+func (a *alertTracker) shouldSend(key string, now time.Time, interval time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	last, ok := a.sent[key]
+	if ok && now.Sub(last) < interval {
+		return false
+	}
+	if a.sent == nil {
+		a.sent = map[string]time.Time{}
+	}
+	a.sent[key] = now
+	return true
+}
+
+// reportLayoutMismatch logs the error and sends a notification. The same error is only sent once per
+// layoutAlertInterval
+func (s *server) reportLayoutMismatch(ctx context.Context, err error) {
+	slog.Log(ctx, slog.LevelError, "spreadsheet layout changed", "err", err.Error())
+	if s.nc == nil || !s.layoutAlerts.shouldSend(err.Error(), time.Now(), layoutAlertInterval) {
+		return
+	}
+
+	sendErr := s.nc.send("AZStocker Layout Changed", err.Error())
+	if sendErr != nil {
+		slog.Log(ctx, slog.LevelError, "error sending layout notification", "err", sendErr)
+	}
+}
+
+// dataErrorPage responds to an error from getting stocking data with a page that explains the problem
+func (s *server) dataErrorPage(w http.ResponseWriter, r *http.Request, err error, waters []string) {
+	status := errorStatus(err)
+	switch status {
+	case http.StatusInternalServerError:
+		slog.Log(r.Context(), slog.LevelError, "failed to get data", "err", err.Error())
+		http.Error(w, err.Error(), status)
+		return
+	case http.StatusBadGateway:
+		s.reportLayoutMismatch(r.Context(), err)
+	default:
+		slog.Log(r.Context(), slog.LevelWarn, "failed to get data", "status", status, "err", err.Error())
+	}
+
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+
+	lang := language(w, r)
+	message := errorMessage(lang, err, waters)
+	tmpl, err := loadTemplates()
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to parse template", "err", err.Error())
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	err = executeTemplate(r.Context(), tmpl, w, "error", withLanguage(r, lang, map[string]any{
+		"program": "error",
+		"title":   fmt.Sprintf("error.title.%d", status),
+		"message": message,
+	}))
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to execute template", "err", err.Error())
+	}
+}
+
+// dataErrorText is dataErrorPage for responses that are not HTML, like feeds and images
+func (s *server) dataErrorText(w http.ResponseWriter, r *http.Request, err error) {
+	status := errorStatus(err)
+	switch status {
+	case http.StatusInternalServerError:
+		slog.Log(r.Context(), slog.LevelError, "failed to get data", "err", err.Error())
+		http.Error(w, err.Error(), status)
+		return
+	case http.StatusBadGateway:
+		s.reportLayoutMismatch(r.Context(), err)
+	default:
+		slog.Log(r.Context(), slog.LevelWarn, "failed to get data", "status", status, "err", err.Error())
+	}
+
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
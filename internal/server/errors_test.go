@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/transport"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"UnknownProgram", fmt.Errorf("%w: %q", azstocker.ErrUnknownProgram, "fall"), http.StatusBadRequest},
+		{"NoMatchingWaters", fmt.Errorf("%w: Lynx Lake", azstocker.ErrNoMatchingWaters), http.StatusNotFound},
+		{"Unavailable", &azstocker.UpstreamError{StatusCode: http.StatusServiceUnavailable}, http.StatusServiceUnavailable},
+		{"QuotaExceeded", &azstocker.UpstreamError{StatusCode: http.StatusTooManyRequests}, http.StatusServiceUnavailable},
+		{"LocalQuota", &azstocker.UpstreamError{Err: transport.ErrQuotaExhausted}, http.StatusServiceUnavailable},
+		{"InvalidAPIKey", &azstocker.UpstreamError{StatusCode: http.StatusForbidden}, http.StatusInternalServerError},
+		{"LocalQuotaExceeded", &azstocker.UpstreamError{Err: fmt.Errorf("%w: no tokens", transport.ErrQuotaExhausted)}, http.StatusServiceUnavailable},
+		{"LayoutMismatch", fmt.Errorf("%w: no dates", azstocker.ErrLayoutMismatch), http.StatusBadGateway},
+		{"SheetNotFound", azstocker.ErrSheetNotFound, http.StatusNotFound},
+		{"Other", errors.New("oops"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, errorStatus(tt.err))
+		})
+	}
+}
+
+func TestAlertTracker(t *testing.T) {
+	var alerts alertTracker
+	now := time.Now()
+
+	assert.True(t, alerts.shouldSend("layout", now, time.Hour))
+	assert.False(t, alerts.shouldSend("layout", now.Add(time.Minute), time.Hour))
+	assert.True(t, alerts.shouldSend("other", now.Add(time.Minute), time.Hour))
+	assert.True(t, alerts.shouldSend("layout", now.Add(time.Hour), time.Hour))
+}
+
+func TestDataErrorPage(t *testing.T) {
+	upstreamStatus := http.StatusServiceUnavailable
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(upstreamStatus)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"error":{"code":%d,"message":"upstream error"}}`, upstreamStatus)))
+	}))
+	defer ts.Close()
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+
+	s, err := newServer(srv, "http://example.com")
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	t.Run("Unavailable", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/winter?lang=es", http.NoBody))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, retryAfter, w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "Calendario no disponible")
+		assert.NotContains(t, w.Body.String(), internalErrorMessage)
+	})
+
+	t.Run("Feed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/winter/feed.atom", http.NoBody))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "Service Unavailable\n", w.Body.String())
+	})
+
+	t.Run("SheetNotFound", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.dataErrorPage(w, httptest.NewRequest(http.MethodGet, "/winter", http.NoBody), fmt.Errorf("%w: no seasons", azstocker.ErrSheetNotFound), nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "The schedule for this season hasn&#39;t been published yet.")
+	})
+
+	t.Run("LayoutMismatch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.dataErrorText(w, httptest.NewRequest(http.MethodGet, "/winter/feed.atom", http.NoBody), fmt.Errorf("%w: no dates", azstocker.ErrLayoutMismatch))
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Equal(t, "Bad Gateway\n", w.Body.String())
+	})
+
+	t.Run("InvalidAPIKey", func(t *testing.T) {
+		upstreamStatus = http.StatusForbidden
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/winter", http.NoBody))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, internalErrorMessage, w.Body.String())
+	})
+}
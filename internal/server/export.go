@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/export"
+)
+
+// exportCSV responds with the program's stockings for the current season in the same long format as the
+// export command. Previous seasons are only available from the export command since they each need to be
+// fetched
+func (s *server) exportCSV(program azstocker.Program) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		seasons, err := azstocker.Seasons(r.Context(), s.srv, program, s.sourceOptions()...)
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+		season, ok := azstocker.CurrentSeason(seasons, azstocker.Now())
+		if !ok {
+			s.dataErrorText(w, r, fmt.Errorf("%w: no seasons found for program %q", azstocker.ErrSheetNotFound, program))
+			return
+		}
+
+		stockingData, err := s.getStockingData(r.Context(), program, nil, azstocker.WithSeason(season))
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+
+		// write to a buffer first so an error can still be a 500 response
+		var buf bytes.Buffer
+		err = export.WriteCSV(&buf, azstocker.NewRecords(season, stockingData))
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to write export", "err", err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+string(program)+`.csv"`)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCSV(t *testing.T) {
+	dir := t.TempDir()
+	schedule := strings.Join([]string{
+		"", "", "",
+		",October,,November",
+		",7,14,4",
+		"", "", "",
+		"Lynx Lake,X,,C",
+	}, "\n")
+	err := os.WriteFile(filepath.Join(dir, "2024-25 Winter.csv"), []byte(schedule), 0o600)
+	assert.NoError(t, err)
+
+	s, err := newServer(nil, "http://example.com", WithScheduleDir(dir))
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	t.Run("Successful", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export/winter.csv", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `program,season,water,date,species,status
+winter,2024-25 Winter,Lynx Lake,2024-10-07,Trout,
+winter,2024-25 Winter,Lynx Lake,2024-11-04,Catfish,
+`, w.Body.String())
+	})
+
+	t.Run("Reconciled", func(t *testing.T) {
+		report := filepath.Join(t.TempDir(), "report.csv")
+		err := os.WriteFile(report, []byte("Date,Water\n10/08/2024,Lynx Lake\n"), 0o600)
+		assert.NoError(t, err)
+
+		s, err := newServer(nil, "http://example.com", WithScheduleDir(dir), WithStockingReports(report))
+		assert.NoError(t, err)
+		s.reports.load(context.Background())
+
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export/winter.csv", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `program,season,water,date,species,status
+winter,2024-25 Winter,Lynx Lake,2024-10-07,Trout,completed
+winter,2024-25 Winter,Lynx Lake,2024-11-04,Catfish,unconfirmed
+`, w.Body.String())
+	})
+
+	t.Run("MissingSchedule", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export/cfp.csv", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,538 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+)
+
+const (
+	atomContentType = "application/atom+xml; charset=utf-8"
+
+	// maxChanges is the number of schedule changes kept for each program
+	maxChanges = 200
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Updated  string       `xml:"updated"`
+	Link     atomLink     `xml:"link"`
+	Summary  string       `xml:"summary"`
+	Category atomCategory `xml:"category"`
+
+	updated time.Time
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// scheduleChange is a difference in a water's stocking schedule between two fetches
+type scheduleChange struct {
+	Water    string           `json:"water"`
+	Detected time.Time        `json:"detected"`
+	Added    []azstocker.Week `json:"added"`
+	Removed  []azstocker.Week `json:"removed"`
+	// WaterRemoved is set when the water is no longer in the schedule
+	WaterRemoved bool `json:"water_removed,omitempty"`
+}
+
+type seenWeek struct {
+	program azstocker.Program
+	water   string
+	week    azstocker.Week
+}
+
+// changeTracker compares each fetch with the previous one to detect schedule changes. It also keeps the
+// time that each scheduled stocking was first seen so feed entries have stable updated timestamps. It is
+// only kept in memory unless it has a file from WithChangesFile
+type changeTracker struct {
+	mu          sync.Mutex
+	path        string
+	seasons     map[azstocker.Program]string
+	snapshots   map[azstocker.Program]map[string][]azstocker.Week
+	firstSeen   map[seenWeek]time.Time
+	lastChanged map[azstocker.Program]map[string]time.Time
+	changes     map[azstocker.Program][]scheduleChange
+}
+
+// WithChangesFile saves detected schedule changes to a JSON file so the changes feed and the times that
+// stockings were first seen are kept when the server restarts
+func WithChangesFile(path string) Option {
+	return func(s *server) error {
+		if path == "" {
+			return nil
+		}
+		err := s.changes.load(path)
+		if err != nil {
+			return fmt.Errorf("error loading schedule changes: %w", err)
+		}
+		return nil
+	}
+}
+
+func (c *changeTracker) init(program azstocker.Program) {
+	if c.snapshots == nil {
+		c.seasons = map[azstocker.Program]string{}
+		c.snapshots = map[azstocker.Program]map[string][]azstocker.Week{}
+		c.firstSeen = map[seenWeek]time.Time{}
+		c.lastChanged = map[azstocker.Program]map[string]time.Time{}
+		c.changes = map[azstocker.Program][]scheduleChange{}
+	}
+	if c.snapshots[program] == nil {
+		c.snapshots[program] = map[string][]azstocker.Week{}
+		c.lastChanged[program] = map[string]time.Time{}
+	}
+}
+
+// record updates the snapshots with new data from a Season. The first fetch of a water is used as the baseline
+// so it does not create a change. When the Season changes, every water gets a new baseline instead of a change.
+// Waters are only checked for removal from the schedule when the data is complete, so filtered fetches do not
+// remove the waters they leave out. If there is a file, it is saved when anything changes
+func (c *changeTracker) record(program azstocker.Program, season string, complete bool, stockingData azstocker.StockingData, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.init(program)
+
+	modified := false
+	if c.seasons[program] != season {
+		// a file saved before Seasons were tracked has the current Season, so it is not reset
+		if c.seasons[program] != "" {
+			c.reset(program)
+		}
+		c.seasons[program] = season
+		modified = true
+	}
+
+	for _, calendar := range stockingData {
+		current := stockedWeeks(calendar.Data)
+		for _, week := range current {
+			key := seenWeek{program, calendar.WaterName, week}
+			if _, ok := c.firstSeen[key]; !ok {
+				c.firstSeen[key] = now
+				modified = true
+			}
+		}
+
+		previous, ok := c.snapshots[program][calendar.WaterName]
+		c.snapshots[program][calendar.WaterName] = current
+		if !ok {
+			c.lastChanged[program][calendar.WaterName] = now
+			modified = true
+			continue
+		}
+
+		added := weeksDifference(current, previous)
+		removed := weeksDifference(previous, current)
+		for _, week := range removed {
+			delete(c.firstSeen, seenWeek{program, calendar.WaterName, week})
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		modified = true
+		c.lastChanged[program][calendar.WaterName] = now
+		c.addChange(program, scheduleChange{
+			Water:    calendar.WaterName,
+			Detected: now,
+			Added:    added,
+			Removed:  removed,
+		})
+	}
+
+	if complete {
+		for water, previous := range c.snapshots[program] {
+			if slices.ContainsFunc(stockingData, func(calendar azstocker.Calendar) bool { return calendar.WaterName == water }) {
+				continue
+			}
+
+			modified = true
+			c.removeWater(program, water)
+			c.addChange(program, scheduleChange{
+				Water:        water,
+				Detected:     now,
+				Added:        []azstocker.Week{},
+				Removed:      previous,
+				WaterRemoved: true,
+			})
+		}
+	}
+
+	if !modified || c.path == "" {
+		return nil
+	}
+	return c.save()
+}
+
+// reset removes the program's snapshots so the next data is used as the baseline
+func (c *changeTracker) reset(program azstocker.Program) {
+	for water := range c.snapshots[program] {
+		c.removeWater(program, water)
+	}
+}
+
+func (c *changeTracker) removeWater(program azstocker.Program, water string) {
+	for _, week := range c.snapshots[program][water] {
+		delete(c.firstSeen, seenWeek{program, water, week})
+	}
+	delete(c.snapshots[program], water)
+	delete(c.lastChanged[program], water)
+}
+
+func (c *changeTracker) addChange(program azstocker.Program, change scheduleChange) {
+	c.changes[program] = append(c.changes[program], change)
+	if len(c.changes[program]) > maxChanges {
+		c.changes[program] = c.changes[program][len(c.changes[program])-maxChanges:]
+	}
+}
+
+// savedChanges is the changeTracker in its file. Each week is saved with the time it was first seen
+type savedChanges struct {
+	Seasons map[azstocker.Program]string                `json:"seasons"`
+	Waters  map[azstocker.Program]map[string]savedWater `json:"waters"`
+	Changes map[azstocker.Program][]scheduleChange      `json:"changes"`
+}
+
+type savedWater struct {
+	Weeks       []savedWeek `json:"weeks"`
+	LastChanged time.Time   `json:"last_changed"`
+}
+
+type savedWeek struct {
+	Week      azstocker.Week `json:"week"`
+	FirstSeen time.Time      `json:"first_seen"`
+}
+
+// load reads the file if it exists and saves changes to it from now on
+func (c *changeTracker) load(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	var saved savedChanges
+	err = json.Unmarshal(data, &saved)
+	if err != nil {
+		return fmt.Errorf("error parsing file: %w", err)
+	}
+
+	for program, waters := range saved.Waters {
+		c.init(program)
+		for water, savedWater := range waters {
+			weeks := []azstocker.Week{}
+			for _, week := range savedWater.Weeks {
+				weeks = append(weeks, week.Week)
+				c.firstSeen[seenWeek{program, water, week.Week}] = week.FirstSeen
+			}
+			c.snapshots[program][water] = weeks
+			c.lastChanged[program][water] = savedWater.LastChanged
+		}
+	}
+	for program, changes := range saved.Changes {
+		c.init(program)
+		c.changes[program] = changes
+	}
+	for program, season := range saved.Seasons {
+		c.init(program)
+		c.seasons[program] = season
+	}
+	return nil
+}
+
+// save writes the changeTracker to its file. It is called with the lock held so an older state never
+// replaces a newer one. The file is small and only written when something changes
+func (c *changeTracker) save() error {
+	saved := savedChanges{
+		Seasons: c.seasons,
+		Waters:  map[azstocker.Program]map[string]savedWater{},
+		Changes: c.changes,
+	}
+	for program, waters := range c.snapshots {
+		saved.Waters[program] = map[string]savedWater{}
+		for water, weeks := range waters {
+			savedWeeks := []savedWeek{}
+			for _, week := range weeks {
+				savedWeeks = append(savedWeeks, savedWeek{week, c.firstSeen[seenWeek{program, water, week}]})
+			}
+			saved.Waters[program][water] = savedWater{Weeks: savedWeeks, LastChanged: c.lastChanged[program][water]}
+		}
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("error encoding changes: %w", err)
+	}
+	err = writeFileAtomic(c.path, data)
+	if err != nil {
+		return fmt.Errorf("error saving changes: %w", err)
+	}
+	return nil
+}
+
+func (c *changeTracker) seen(program azstocker.Program, water string, week azstocker.Week) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the Status is from the stocking reports and is not part of the schedule
+	week.Status = ""
+	t, ok := c.firstSeen[seenWeek{program, water, week}]
+	return t, ok
+}
+
+// lastModified returns the last time that the water's schedule changed. This is the first time it was
+// seen if it has not changed since it was first tracked
+func (c *changeTracker) lastModified(program azstocker.Program, water string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.lastChanged[program][water]
+	return t, ok
+}
+
+// recent returns the program's changes, optionally filtered by water
+func (c *changeTracker) recent(program azstocker.Program, waters []string) []scheduleChange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := []scheduleChange{}
+	for _, change := range c.changes[program] {
+		if len(waters) > 0 && !slices.ContainsFunc(waters, func(w string) bool { return strings.EqualFold(w, change.Water) }) {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// lastStockedKey chooses the message for the most recent stocking using its Status from the stocking reports
+func lastStockedKey(week azstocker.Week) string {
+	switch {
+	case week.Status == azstocker.Cancelled:
+		return "feed.cancelled"
+	case week.Status == azstocker.Completed && week.Stock == azstocker.UnknownFish:
+		return "feed.completed"
+	case week.Status == azstocker.Unconfirmed, week.Status == "" && week.Stock == azstocker.UnknownFish:
+		return "feed.unconfirmed"
+	default:
+		return "feed.stocked"
+	}
+}
+
+func stockedWeeks(weeks []azstocker.Week) []azstocker.Week {
+	return slices.DeleteFunc(slices.Clone(weeks), func(w azstocker.Week) bool {
+		return w.Stock == azstocker.NoneFish
+	})
+}
+
+// weeksDifference returns weeks in a that are not in b
+func weeksDifference(a, b []azstocker.Week) []azstocker.Week {
+	result := []azstocker.Week{}
+	for _, week := range a {
+		if !slices.Contains(b, week) {
+			result = append(result, week)
+		}
+	}
+	return result
+}
+
+// programFeed responds with an Atom feed of the most recent and next stocking for each water
+func (s *server) programFeed(program azstocker.Program) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		waters := query{r}.StringSlice(watersQueryParam)
+		lang := language(w, r)
+
+		stockingData, err := s.getStockingData(r.Context(), program, waters)
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+
+		entries := []atomEntry{}
+		for _, calendar := range stockingData {
+			last := calendar.Last()
+			if last.Year != 0 {
+				key := lastStockedKey(last)
+				entries = append(entries, s.stockingEntry(lang, program, calendar.WaterName, last, "stocked", key, last.Time()))
+			}
+
+			next := calendar.Next()
+			if next.Year != 0 {
+				updated, ok := s.changes.seen(program, calendar.WaterName, next)
+				if !ok {
+					updated = time.Now()
+				}
+				entries = append(entries, s.stockingEntry(lang, program, calendar.WaterName, next, "scheduled", "feed.scheduled", updated))
+			}
+		}
+
+		s.writeFeed(w, r, lang, program, "feed.atom", lang.T("feed.title", lang.T("program."+string(program))), entries)
+	}
+}
+
+// changesFeed responds with an Atom feed of schedule changes detected since the server started
+func (s *server) changesFeed(program azstocker.Program) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		waters := query{r}.StringSlice(watersQueryParam)
+		lang := language(w, r)
+		weekLang := azstocker.Language(lang)
+
+		// fetching data will detect any new changes
+		_, err := s.getStockingData(r.Context(), program, nil)
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+
+		entries := []atomEntry{}
+		for _, change := range s.changes.recent(program, waters) {
+			var summary []string
+			for _, week := range change.Added {
+				summary = append(summary, lang.T("feed.added", week.StringIn(weekLang)))
+			}
+			for _, week := range change.Removed {
+				summary = append(summary, lang.T("feed.removed", week.StringIn(weekLang)))
+			}
+
+			titleKey := "feed.changed"
+			if change.WaterRemoved {
+				titleKey = "feed.waterRemoved"
+			}
+
+			entries = append(entries, atomEntry{
+				ID:       s.tagURI(program, change.Water, "changed", change.Detected.UTC().Format(time.RFC3339)),
+				Title:    lang.T(titleKey, change.Water),
+				Link:     atomLink{Href: s.waterURL(program, change.Water)},
+				Summary:  strings.Join(summary, "\n"),
+				Category: atomCategory{Term: string(program)},
+				updated:  change.Detected,
+			})
+		}
+
+		s.writeFeed(w, r, lang, program, "changes.atom", lang.T("feed.changesTitle", lang.T("program."+string(program))), entries)
+	}
+}
+
+func (s *server) stockingEntry(lang i18n.Language, program azstocker.Program, water string, week azstocker.Week, kind, key string, updated time.Time) atomEntry {
+	weekLang := azstocker.Language(lang)
+	return atomEntry{
+		ID:       s.tagURI(program, water, kind, week.Time().Format(time.DateOnly)),
+		Title:    lang.T(key, water, week.Stock.NameIn(weekLang), week.DateIn(weekLang)),
+		Link:     atomLink{Href: s.waterURL(program, water)},
+		Summary:  week.StringIn(weekLang),
+		Category: atomCategory{Term: string(program)},
+		updated:  updated,
+	}
+}
+
+// writeFeed sorts the entries with the newest first and uses the newest entry as the feed's updated time
+// so it only changes when there is something new
+func (s *server) writeFeed(w http.ResponseWriter, r *http.Request, lang i18n.Language, program azstocker.Program, name, title string, entries []atomEntry) {
+	slices.SortStableFunc(entries, func(a, b atomEntry) int {
+		return cmp.Or(b.updated.Compare(a.updated), strings.Compare(a.ID, b.ID))
+	})
+
+	updated := time.Unix(0, 0)
+	if len(entries) > 0 {
+		updated = entries[0].updated
+	} else if status := s.statusSnapshot()[program]; status.LastSuccess != nil {
+		updated = *status.LastSuccess
+	}
+	for i := range entries {
+		entries[i].Updated = entries[i].updated.UTC().Format(time.RFC3339)
+	}
+
+	selfURL := url.URL{Path: "/" + string(program) + "/" + name, RawQuery: r.URL.RawQuery}
+	feed := atomFeed{
+		ID:      s.tagURI(program, r.URL.Query().Get(watersQueryParam), strings.TrimSuffix(name, ".atom"), string(lang)),
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "AZStocker", URI: s.urlBase},
+		Links: []atomLink{
+			{Href: s.urlBase + selfURL.String(), Rel: "self", Type: "application/atom+xml"},
+			{Href: s.urlBase + "/" + string(program), Rel: "alternate", Type: "text/html"},
+		},
+		Entries: entries,
+	}
+
+	w.Header().Set("Content-Type", atomContentType)
+	_, _ = w.Write([]byte(xml.Header))
+	err := xml.NewEncoder(w).Encode(feed)
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to write feed", "err", err.Error())
+	}
+}
+
+// tagURI creates a permanent ID for a feed or entry using the tag URI scheme (RFC 4151)
+func (s *server) tagURI(program azstocker.Program, parts ...string) string {
+	host := "azstocker.com"
+	parsed, err := url.Parse(s.urlBase)
+	if err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	escaped := []string{string(program)}
+	for _, part := range parts {
+		if part != "" {
+			escaped = append(escaped, url.PathEscape(part))
+		}
+	}
+	return fmt.Sprintf("tag:%s,2024:%s", host, strings.Join(escaped, "/"))
+}
+
+// feedURL is the path of the program's feed, which is linked from the calendar page
+func feedURL(program azstocker.Program, waters []string) string {
+	if len(waters) == 0 {
+		return "/" + string(program) + "/feed.atom"
+	}
+	query := url.Values{watersQueryParam: []string{strings.Join(waters, ",")}}
+	return "/" + string(program) + "/feed.atom?" + query.Encode()
+}
+
+func (s *server) waterURL(program azstocker.Program, water string) string {
+	query := url.Values{watersQueryParam: []string{water}}
+	return fmt.Sprintf("%s/%s?%s", s.urlBase, program, query.Encode())
+}
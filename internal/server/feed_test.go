@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeTracker(t *testing.T) {
+	var c changeTracker
+
+	week1 := azstocker.Week{Month: time.October, Day: 21, Year: 2024, Stock: azstocker.Trout}
+	week2 := azstocker.Week{Month: time.October, Day: 28, Year: 2024, Stock: azstocker.Trout}
+	empty := azstocker.Week{Month: time.November, Day: 4, Year: 2024, Stock: azstocker.NoneFish}
+
+	first := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	c.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1, empty}},
+	}, first)
+	assert.Empty(t, c.recent(azstocker.WinterProgram, nil))
+
+	second := first.Add(24 * time.Hour)
+	c.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2, empty}},
+	}, second)
+
+	changes := c.recent(azstocker.WinterProgram, nil)
+	assert.Equal(t, []scheduleChange{{
+		Water:    "LYNX LAKE",
+		Detected: second,
+		Added:    []azstocker.Week{week2},
+		Removed:  []azstocker.Week{week1},
+	}}, changes)
+	assert.Empty(t, c.recent(azstocker.WinterProgram, []string{"WATSON LAKE"}))
+	assert.Len(t, c.recent(azstocker.WinterProgram, []string{"lynx lake"}), 1)
+
+	seen, ok := c.seen(azstocker.WinterProgram, "LYNX LAKE", week2)
+	assert.True(t, ok)
+	assert.Equal(t, second, seen)
+
+	_, ok = c.seen(azstocker.WinterProgram, "LYNX LAKE", week1)
+	assert.False(t, ok)
+
+	t.Run("UnchangedDataDoesNotUpdate", func(t *testing.T) {
+		c.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2, empty}},
+		}, second.Add(time.Hour))
+
+		assert.Len(t, c.recent(azstocker.WinterProgram, nil), 1)
+		seen, _ := c.seen(azstocker.WinterProgram, "LYNX LAKE", week2)
+		assert.Equal(t, second, seen)
+	})
+
+	t.Run("FilteredDataDoesNotRemoveWaters", func(t *testing.T) {
+		c.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2, empty}},
+			{WaterName: "WATSON LAKE", Data: []azstocker.Week{week1, empty}},
+		}, second.Add(2*time.Hour))
+		c.record(azstocker.WinterProgram, "2024-25 Winter", false, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2, empty}},
+		}, second.Add(3*time.Hour))
+
+		assert.Len(t, c.recent(azstocker.WinterProgram, nil), 1)
+		_, ok := c.lastModified(azstocker.WinterProgram, "WATSON LAKE")
+		assert.True(t, ok)
+	})
+
+	t.Run("RemovedWater", func(t *testing.T) {
+		removed := second.Add(4 * time.Hour)
+		c.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2, empty}},
+		}, removed)
+
+		changes := c.recent(azstocker.WinterProgram, []string{"WATSON LAKE"})
+		assert.Equal(t, []scheduleChange{{
+			Water:        "WATSON LAKE",
+			Detected:     removed,
+			Added:        []azstocker.Week{},
+			Removed:      []azstocker.Week{week1},
+			WaterRemoved: true,
+		}}, changes)
+
+		_, ok := c.lastModified(azstocker.WinterProgram, "WATSON LAKE")
+		assert.False(t, ok)
+		_, ok = c.seen(azstocker.WinterProgram, "WATSON LAKE", week1)
+		assert.False(t, ok)
+	})
+
+	t.Run("SeasonRollover", func(t *testing.T) {
+		numChanges := len(c.recent(azstocker.WinterProgram, nil))
+
+		nextWeek := azstocker.Week{Month: time.October, Day: 20, Year: 2025, Stock: azstocker.Trout}
+		rollover := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+		c.record(azstocker.WinterProgram, "2025-26 Winter", true, azstocker.StockingData{
+			{WaterName: "GOLDWATER LAKE", Data: []azstocker.Week{nextWeek}},
+		}, rollover)
+
+		// the new Season is a new baseline, so nothing changed and the missing waters are not removed
+		assert.Len(t, c.recent(azstocker.WinterProgram, nil), numChanges)
+		_, ok := c.lastModified(azstocker.WinterProgram, "LYNX LAKE")
+		assert.False(t, ok)
+		_, ok = c.seen(azstocker.WinterProgram, "LYNX LAKE", week2)
+		assert.False(t, ok)
+
+		seen, ok := c.seen(azstocker.WinterProgram, "GOLDWATER LAKE", nextWeek)
+		assert.True(t, ok)
+		assert.Equal(t, rollover, seen)
+	})
+}
+
+func TestChangeTrackerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.json")
+
+	s, err := newServer(nil, "http://example.com", WithChangesFile(path))
+	assert.NoError(t, err)
+
+	week1 := azstocker.Week{Month: time.October, Day: 21, Year: 2024, Stock: azstocker.Trout}
+	week2 := azstocker.Week{Month: time.October, Day: 28, Year: 2024, Stock: azstocker.Trout}
+	first := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1}},
+	}, first))
+	assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1, week2}},
+	}, second))
+
+	// a new server has the same changes after loading the file
+	s, err = newServer(nil, "http://example.com", WithChangesFile(path))
+	assert.NoError(t, err)
+
+	changes := s.changes.recent(azstocker.WinterProgram, nil)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, []azstocker.Week{week2}, changes[0].Added)
+		assert.True(t, second.Equal(changes[0].Detected))
+	}
+
+	seen, ok := s.changes.seen(azstocker.WinterProgram, "LYNX LAKE", week1)
+	assert.True(t, ok)
+	assert.True(t, first.Equal(seen))
+
+	lastModified, ok := s.changes.lastModified(azstocker.WinterProgram, "LYNX LAKE")
+	assert.True(t, ok)
+	assert.True(t, second.Equal(lastModified))
+
+	// the same data is not a change after restarting
+	assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1, week2}},
+	}, second.Add(time.Hour)))
+	assert.Len(t, s.changes.recent(azstocker.WinterProgram, nil), 1)
+
+	t.Run("SeasonIsSaved", func(t *testing.T) {
+		s, err := newServer(nil, "http://example.com", WithChangesFile(path))
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2025-26 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week2}},
+		}, second.Add(2*time.Hour)))
+		assert.Len(t, s.changes.recent(azstocker.WinterProgram, nil), 1)
+	})
+
+	t.Run("InvalidFile", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "changes.json")
+		assert.NoError(t, os.WriteFile(invalid, []byte("not json"), 0o600))
+
+		_, err := newServer(nil, "http://example.com", WithChangesFile(invalid))
+		assert.ErrorContains(t, err, "error loading schedule changes")
+	})
+}
+
+func TestWriteFeed(t *testing.T) {
+	s := &server{urlBase: "https://azstocker.com"}
+	week := azstocker.Week{Month: time.October, Day: 21, Year: 2024, Stock: azstocker.Trout}
+
+	older := s.stockingEntry(i18n.English, azstocker.WinterProgram, "LYNX LAKE", week, "stocked", "feed.stocked", week.Time())
+	newer := s.stockingEntry(i18n.English, azstocker.WinterProgram, "WATSON LAKE", week, "scheduled", "feed.scheduled", week.Time().Add(time.Hour))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/winter/feed.atom", http.NoBody)
+	s.writeFeed(w, r, i18n.English, azstocker.WinterProgram, "feed.atom", "Winter", []atomEntry{older, newer})
+	assert.Equal(t, atomContentType, w.Header().Get("Content-Type"))
+
+	var feed atomFeed
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "2024-10-21T08:00:00Z", feed.Updated)
+	assert.Len(t, feed.Entries, 2)
+	assert.Equal(t, "tag:azstocker.com,2024:winter/WATSON%20LAKE/scheduled/2024-10-21", feed.Entries[0].ID)
+	assert.Equal(t, "WATSON LAKE scheduled for Trout stocking on 2024 October 21", feed.Entries[0].Title)
+	assert.Equal(t, "LYNX LAKE stocked with Trout on 2024 October 21", feed.Entries[1].Title)
+	assert.Equal(t, "https://azstocker.com/winter?waters=LYNX+LAKE", feed.Entries[1].Link.Href)
+}
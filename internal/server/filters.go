@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+// parseFilters creates filters from the query parameters:
+//   - from and to: dates like 2024-10-21 to only show stockings in the range
+//   - days: only show stockings in the next number of days
+//   - species: comma-separated fish like trout,catfish
+//   - upcoming: only show waters with an upcoming stocking
+//   - since: date to only show waters stocked since then
+func parseFilters(r *http.Request) ([]azstocker.Filter, error) {
+	q := query{r}
+	values := r.URL.Query()
+	return azstocker.ParseFilters(azstocker.FilterOptions{
+		From:     values.Get("from"),
+		To:       values.Get("to"),
+		Days:     values.Get("days"),
+		Species:  q.StringSlice("species"),
+		Upcoming: q.Bool("upcoming"),
+		Since:    values.Get("since"),
+	})
+}
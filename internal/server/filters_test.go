@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilters(t *testing.T) {
+	now := time.Now()
+	weekAt := func(t time.Time, stock azstocker.Fish) azstocker.Week {
+		return azstocker.Week{Year: t.Year(), Month: t.Month(), Day: t.Day(), Stock: stock}
+	}
+	data := azstocker.StockingData{
+		{WaterName: "Catfish Lake", Data: []azstocker.Week{weekAt(now.AddDate(0, 0, 3), azstocker.Catfish)}},
+		{WaterName: "Trout Lake", Data: []azstocker.Week{weekAt(now.AddDate(0, 0, 20), azstocker.Trout)}},
+		{WaterName: "Old Lake", Data: []azstocker.Week{weekAt(now.AddDate(0, 0, -20), azstocker.Trout)}},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    []string
+		expectedErr string
+	}{
+		{"None", "", []string{"Catfish Lake", "Trout Lake", "Old Lake"}, ""},
+		{"Species", "species=catfish,trout", []string{"Catfish Lake", "Trout Lake", "Old Lake"}, ""},
+		{"CatfishThisWeek", "species=catfish&days=7", []string{"Catfish Lake"}, ""},
+		{"Upcoming", "upcoming=true", []string{"Catfish Lake", "Trout Lake"}, ""},
+		{"Since", "since=" + now.AddDate(0, 0, -30).Format(time.DateOnly), []string{"Old Lake"}, ""},
+		{"From", "from=" + now.AddDate(0, 0, 10).Format(time.DateOnly), []string{"Trout Lake"}, ""},
+		{"InvalidDate", "from=October", nil, `invalid from: error parsing date: parsing time "October" as "2006-01-02": cannot parse "October" as "2006"`},
+		{"InvalidDays", "days=-1", nil, `invalid days: "-1"`},
+		{"InvalidSpecies", "species=bass", nil, `unknown species "bass"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := parseFilters(httptest.NewRequest(http.MethodGet, "/cfp?"+tt.query, http.NoBody))
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			names := []string{}
+			for _, calendar := range data.Filter(filters...) {
+				names = append(names, calendar.WaterName)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+const defaultReadyMaxAge = 24 * time.Hour
+
+var errStaleData = errors.New("data is stale")
+
+var programs = azstocker.Programs
+
+// programStatus keeps track of the most recent attempts to get data for a Program
+type programStatus struct {
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	// LastFetch is when the data was fetched from upstream. It is older than LastSuccess when the data came
+	// from the HTTP cache
+	LastFetch     *time.Time `json:"last_fetch,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	AgeSeconds    *float64   `json:"age_seconds,omitempty"`
+	ParseWarnings int        `json:"parse_warnings"`
+	Waters        int        `json:"waters"`
+}
+
+func WithReadyMaxAge(maxAge time.Duration) Option {
+	return func(s *server) error {
+		s.readyMaxAge = maxAge
+		return nil
+	}
+}
+
+// registerHealthRoutes adds health and status endpoints to the mux. These are used on the main and metrics
+// listeners
+func (s *server) registerHealthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+	mux.HandleFunc("GET /status", s.statusHandler)
+}
+
+// healthz responds OK as long as the process is running
+func (s *server) healthz(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+}
+
+// readyz checks that each program has fresh data. If data is stale, it will attempt to fetch it. It is still
+// not ready if the fetch only gets stale data from the HTTP cache
+func (s *server) readyz(w http.ResponseWriter, r *http.Request) {
+	failures := map[azstocker.Program]string{}
+	for _, p := range programs {
+		if s.isFresh(p) {
+			continue
+		}
+
+		_, err := s.getStockingData(r.Context(), p, []string{})
+		if err == nil && !s.isFresh(p) {
+			err = errStaleData
+		}
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelWarn, "readiness check failed", "program", p, "err", err.Error())
+			failures[p] = err.Error()
+		}
+	}
+
+	if len(failures) > 0 {
+		writeJSON(r.Context(), w, http.StatusServiceUnavailable, map[string]any{"ready": false, "errors": failures})
+		return
+	}
+	writeJSON(r.Context(), w, http.StatusOK, map[string]any{"ready": true})
+}
+
+// statusHandler responds with the latest status of each program
+func (s *server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(r.Context(), w, http.StatusOK, map[string]any{"programs": s.statusSnapshot()})
+}
+
+// recordFetch updates the program's status after an attempt to get data. fetchedAt is when the data was
+// fetched from upstream, or zero if it is not known. numWaters and parseWarnings are -1 when the data is
+// filtered or from another Season, so they do not replace the counts for the whole schedule
+func (s *server) recordFetch(program azstocker.Program, numWaters, parseWarnings int, fetchedAt time.Time, err error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	if s.status == nil {
+		s.status = map[azstocker.Program]*programStatus{}
+	}
+	status, ok := s.status[program]
+	if !ok {
+		status = &programStatus{}
+		s.status[program] = status
+	}
+
+	now := time.Now()
+	status.LastAttempt = &now
+	if err != nil {
+		status.LastError = err.Error()
+		return
+	}
+
+	status.LastSuccess = &now
+	if !fetchedAt.IsZero() && (status.LastFetch == nil || fetchedAt.After(*status.LastFetch)) {
+		status.LastFetch = &fetchedAt
+	}
+	status.LastError = ""
+	if parseWarnings >= 0 {
+		status.ParseWarnings = parseWarnings
+	}
+	if numWaters >= 0 {
+		status.Waters = numWaters
+	}
+}
+
+func (s *server) isFresh(program azstocker.Program) bool {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	status, ok := s.status[program]
+	if !ok || status.LastFetch == nil {
+		return false
+	}
+
+	maxAge := s.readyMaxAge
+	if maxAge == 0 {
+		maxAge = defaultReadyMaxAge
+	}
+	return time.Since(*status.LastFetch) < maxAge
+}
+
+func (s *server) statusSnapshot() map[azstocker.Program]programStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	result := map[azstocker.Program]programStatus{}
+	for _, p := range programs {
+		status, ok := s.status[p]
+		if !ok {
+			result[p] = programStatus{}
+			continue
+		}
+
+		snapshot := *status
+		if snapshot.LastFetch != nil {
+			age := time.Since(*snapshot.LastFetch).Seconds()
+			snapshot.AgeSeconds = &age
+		}
+		result[p] = snapshot
+	}
+	return result
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		slog.Log(ctx, slog.LevelError, "failed to write JSON response", "err", err.Error())
+	}
+}
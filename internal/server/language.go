@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+)
+
+const (
+	langQueryParam = "lang"
+
+	// langCookie keeps a language that was explicitly chosen so it is used when navigating to other pages
+	langCookie = "azstocker_lang"
+)
+
+type languageLink struct {
+	Lang   i18n.Language
+	Name   string
+	URL    string
+	Active bool
+}
+
+// language chooses the language from the lang query parameter, a previously chosen language, or the
+// Accept-Language header
+func language(w http.ResponseWriter, r *http.Request) i18n.Language {
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+
+	lang, ok := i18n.Parse(r.URL.Query().Get(langQueryParam))
+	if ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     langCookie,
+			Value:    string(lang),
+			Path:     "/",
+			MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return lang
+	}
+
+	cookie, err := r.Cookie(langCookie)
+	if err == nil {
+		lang, ok := i18n.Parse(cookie.Value)
+		if ok {
+			return lang
+		}
+	}
+
+	return i18n.Match(r.Header.Get("Accept-Language"))
+}
+
+// queryLanguage chooses the language from only the lang query parameter. It is used for publicly cached
+// responses, so it doesn't depend on the request's cookies or headers and doesn't set a cookie
+func queryLanguage(r *http.Request) i18n.Language {
+	lang, ok := i18n.Parse(r.URL.Query().Get(langQueryParam))
+	if !ok {
+		return i18n.Default
+	}
+	return lang
+}
+
+// languageLinks creates links to the current page in each supported language
+func languageLinks(r *http.Request, current i18n.Language) []languageLink {
+	links := []languageLink{}
+	for _, lang := range i18n.Languages {
+		query := r.URL.Query()
+		query.Set(langQueryParam, string(lang))
+		u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+		links = append(links, languageLink{
+			Lang:   lang,
+			Name:   lang.T("language.name"),
+			URL:    u.String(),
+			Active: lang == current,
+		})
+	}
+	return links
+}
+
+// withLanguage adds the language used by the base template to the template data
+func withLanguage(r *http.Request, lang i18n.Language, data map[string]any) map[string]any {
+	data["lang"] = azstocker.Language(lang)
+	data["languages"] = languageLinks(r, lang)
+	return data
+}
@@ -0,0 +1,459 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+const (
+	listIDLength   = 8
+	listIDAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	maxListNameLen = 100
+	maxListWaters  = 50
+	// maxListFormBytes is enough for a name and the longest water names in the schedules
+	maxListFormBytes = 16 << 10
+	// maxStoredLists is how many lists are kept. The oldest lists are removed when there are more
+	maxStoredLists = 10000
+
+	// listsCookie stores the IDs of lists created by this browser so they can be shown on other pages
+	listsCookie   = "azstocker_lists"
+	maxSavedLists = 20
+)
+
+var (
+	errListNotFound = errors.New("list not found")
+	validListID     = regexp.MustCompile("^[" + listIDAlphabet + "]{" + fmt.Sprint(listIDLength) + "}$")
+)
+
+// favoriteList is a named list of waters that can include waters from multiple programs. Lists are
+// never modified after they are created so a shared URL always shows the same waters
+type favoriteList struct {
+	ID      string                         `json:"id"`
+	Name    string                         `json:"name"`
+	Waters  map[azstocker.Program][]string `json:"waters"`
+	Created time.Time                      `json:"created"`
+}
+
+// listStore saves and loads favorite lists
+type listStore interface {
+	get(id string) (favoriteList, error)
+	save(list favoriteList) error
+}
+
+func WithListsDir(dir string) Option {
+	return func(s *server) error {
+		if dir == "" {
+			return nil
+		}
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return fmt.Errorf("error creating lists directory: %w", err)
+		}
+		s.lists = &fileListStore{dir: dir, limit: maxStoredLists}
+		return nil
+	}
+}
+
+// memoryListStore is the default store, which does not keep lists when the server restarts
+type memoryListStore struct {
+	mu    sync.RWMutex
+	lists map[string]favoriteList
+	// order is the list IDs from oldest to newest so the oldest are removed first
+	order []string
+	limit int
+}
+
+func newMemoryListStore() *memoryListStore {
+	return &memoryListStore{lists: map[string]favoriteList{}, limit: maxStoredLists}
+}
+
+func (m *memoryListStore) get(id string) (favoriteList, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list, ok := m.lists[id]
+	if !ok {
+		return favoriteList{}, errListNotFound
+	}
+	return list, nil
+}
+
+func (m *memoryListStore) save(list favoriteList) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.lists[list.ID]; !exists {
+		m.order = append(m.order, list.ID)
+	}
+	m.lists[list.ID] = list
+
+	for len(m.order) > m.limit {
+		delete(m.lists, m.order[0])
+		m.order = m.order[1:]
+	}
+	return nil
+}
+
+// fileListStore saves each list as a JSON file in a directory
+type fileListStore struct {
+	dir   string
+	limit int
+}
+
+func (f *fileListStore) get(id string) (favoriteList, error) {
+	data, err := os.ReadFile(f.filename(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return favoriteList{}, errListNotFound
+	}
+	if err != nil {
+		return favoriteList{}, fmt.Errorf("error reading list: %w", err)
+	}
+
+	var list favoriteList
+	err = json.Unmarshal(data, &list)
+	if err != nil {
+		return favoriteList{}, fmt.Errorf("error parsing list: %w", err)
+	}
+	return list, nil
+}
+
+func (f *fileListStore) save(list favoriteList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("error encoding list: %w", err)
+	}
+
+	err = writeFileAtomic(f.filename(list.ID), data)
+	if err != nil {
+		return fmt.Errorf("error saving list: %w", err)
+	}
+
+	err = f.removeOldest()
+	if err != nil {
+		return fmt.Errorf("error removing old lists: %w", err)
+	}
+	return nil
+}
+
+// removeOldest removes the least recently saved lists when there are more than the limit
+func (f *fileListStore) removeOldest() error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	type listFile struct {
+		name    string
+		modTime time.Time
+	}
+	files := []listFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		files = append(files, listFile{entry.Name(), info.ModTime()})
+	}
+	if len(files) <= f.limit {
+		return nil
+	}
+
+	slices.SortFunc(files, func(a, b listFile) int {
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, file := range files[:len(files)-f.limit] {
+		err = os.Remove(filepath.Join(f.dir, file.name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temporary file and renames it so readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *fileListStore) filename(id string) string {
+	return filepath.Join(f.dir, id+".json")
+}
+
+func newListID() (string, error) {
+	var sb strings.Builder
+	for range listIDLength {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(listIDAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(listIDAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// createList handles a form with a name, a program and comma-separated waters. If extend is set to an existing
+// list ID, the new list will also include that list's waters. New waters must be in the program's schedule
+func (s *server) createList(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxListFormBytes)
+	err := r.ParseForm()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, "form is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	list := favoriteList{
+		Name:    strings.TrimSpace(r.PostForm.Get("name")),
+		Waters:  map[azstocker.Program][]string{},
+		Created: time.Now(),
+	}
+
+	extendID := r.PostForm.Get("extend")
+	if extendID != "" {
+		existing, err := s.getList(extendID)
+		if err != nil {
+			http.Error(w, "list to extend not found", http.StatusBadRequest)
+			return
+		}
+		for program, waters := range existing.Waters {
+			list.Waters[program] = slices.Clone(waters)
+		}
+		if list.Name == "" {
+			list.Name = existing.Name
+		}
+	}
+
+	programStr := r.PostForm.Get("program")
+	if programStr != "" {
+		program, err := azstocker.ParseProgram(programStr)
+		if err != nil {
+			http.Error(w, "invalid program", http.StatusBadRequest)
+			return
+		}
+		scheduled, err := s.scheduledWaters(r.Context(), program)
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+		for _, water := range strings.Split(r.PostForm.Get(watersQueryParam), ",") {
+			water = strings.TrimSpace(water)
+			if water == "" {
+				continue
+			}
+			// use the name from the schedule so the list doesn't keep other names
+			name, ok := scheduled[strings.ToLower(water)]
+			if !ok {
+				http.Error(w, "unknown water", http.StatusBadRequest)
+				return
+			}
+			if !slices.Contains(list.Waters[program], name) {
+				list.Waters[program] = append(list.Waters[program], name)
+			}
+		}
+	}
+
+	numWaters := 0
+	for _, waters := range list.Waters {
+		numWaters += len(waters)
+	}
+	switch {
+	case list.Name == "":
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	case len(list.Name) > maxListNameLen:
+		http.Error(w, "name is too long", http.StatusBadRequest)
+		return
+	case numWaters == 0:
+		http.Error(w, "at least one water is required", http.StatusBadRequest)
+		return
+	case numWaters > maxListWaters:
+		http.Error(w, "too many waters", http.StatusBadRequest)
+		return
+	}
+
+	list.ID, err = newListID()
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to create list ID", "err", err.Error())
+		http.Error(w, internalErrorMessage, http.StatusInternalServerError)
+		return
+	}
+
+	err = s.lists.save(list)
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to save list", "err", err.Error())
+		http.Error(w, internalErrorMessage, http.StatusInternalServerError)
+		return
+	}
+
+	addSavedList(w, r, list.ID, extendID)
+	http.Redirect(w, r, "/l/"+list.ID, http.StatusSeeOther)
+}
+
+// scheduledWaters maps the lowercase name of each water in the program's schedule to its name. Waters are
+// matched without case like the waters query parameter
+func (s *server) scheduledWaters(ctx context.Context, program azstocker.Program) (map[string]string, error) {
+	stockingData, err := s.getStockingData(ctx, program, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, calendar := range stockingData {
+		result[strings.ToLower(calendar.WaterName)] = calendar.WaterName
+	}
+	return result, nil
+}
+
+// getListSchedule shows the schedule for every water in the list, grouped by program
+func (s *server) getListSchedule(w http.ResponseWriter, r *http.Request) {
+	list, err := s.getList(r.PathValue("id"))
+	if errors.Is(err, errListNotFound) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to get list", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type programSchedule struct {
+		Program  azstocker.Program
+		Calendar azstocker.StockingData
+	}
+	schedules := []programSchedule{}
+	for _, program := range programs {
+		waters, ok := list.Waters[program]
+		if !ok {
+			continue
+		}
+
+		// waters can be removed from the schedule after the list is saved
+		stockingData, err := s.getStockingData(r.Context(), program, waters)
+		if errors.Is(err, azstocker.ErrNoMatchingWaters) {
+			continue
+		}
+		if err != nil {
+			s.dataErrorPage(w, r, err, nil)
+			return
+		}
+		stockingData.SortNext()
+		schedules = append(schedules, programSchedule{program, stockingData})
+	}
+
+	tmpl, err := loadTemplates()
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to parse template", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = executeTemplate(r.Context(), tmpl, w, "list", withLanguage(r, language(w, r), map[string]any{
+		"program":       "list",
+		"list":          list,
+		"schedules":     schedules,
+		"shareURL":      s.urlBase + "/l/" + list.ID,
+		"notifyEnabled": s.notifyEnabled(r),
+	}))
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to execute template", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *server) getList(id string) (favoriteList, error) {
+	if !validListID.MatchString(id) {
+		return favoriteList{}, errListNotFound
+	}
+	return s.lists.get(id)
+}
+
+// savedLists gets the lists that were created by this browser
+func (s *server) savedLists(r *http.Request) []favoriteList {
+	result := []favoriteList{}
+	for _, id := range savedListIDs(r) {
+		list, err := s.getList(id)
+		if err != nil {
+			continue
+		}
+		result = append(result, list)
+	}
+	return result
+}
+
+func savedListIDs(r *http.Request) []string {
+	cookie, err := r.Cookie(listsCookie)
+	if err != nil {
+		return nil
+	}
+
+	ids := []string{}
+	for _, id := range strings.Split(cookie.Value, ".") {
+		if validListID.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// addSavedList adds the new list to the cookie. When a list is extended, it replaces the original
+func addSavedList(w http.ResponseWriter, r *http.Request, id, replaceID string) {
+	ids := slices.DeleteFunc(savedListIDs(r), func(existing string) bool {
+		return existing == replaceID || existing == id
+	})
+	ids = append([]string{id}, ids...)
+	if len(ids) > maxSavedLists {
+		ids = ids[:maxSavedLists]
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     listsCookie,
+		Value:    strings.Join(ids, "."),
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
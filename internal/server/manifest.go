@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/static"
+)
+
+const (
+	themeColor      = "#1e87f0"
+	backgroundColor = "#ffffff"
+
+	// favoritesCookie stores the URL of the last visited favorites so it can be used as a shortcut when
+	// installing the app
+	favoritesCookie = "azstocker_favorites"
+)
+
+type webManifest struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	ShortName       string             `json:"short_name"`
+	Description     string             `json:"description"`
+	StartURL        string             `json:"start_url"`
+	Scope           string             `json:"scope"`
+	Display         string             `json:"display"`
+	Orientation     string             `json:"orientation"`
+	BackgroundColor string             `json:"background_color"`
+	ThemeColor      string             `json:"theme_color"`
+	Icons           []manifestIcon     `json:"icons"`
+	Shortcuts       []manifestShortcut `json:"shortcuts"`
+}
+
+type manifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+type manifestShortcut struct {
+	Name      string         `json:"name"`
+	ShortName string         `json:"short_name"`
+	URL       string         `json:"url"`
+	Icons     []manifestIcon `json:"icons,omitempty"`
+}
+
+var manifestIcons = []manifestIcon{
+	{Src: static.URL("icons/icon-192.png"), Sizes: "192x192", Type: "image/png"},
+	{Src: static.URL("icons/icon-512.png"), Sizes: "512x512", Type: "image/png"},
+	{Src: static.URL("icons/icon-maskable-512.png"), Sizes: "512x512", Type: "image/png", Purpose: "maskable"},
+	{Src: static.URL("icons/icon.svg"), Sizes: "any", Type: "image/svg+xml"},
+}
+
+// manifest.json enables PWA for mobile devices
+func (s *server) pwaManifest(w http.ResponseWriter, r *http.Request) {
+	shortcutIcons := manifestIcons[:1]
+	manifest := webManifest{
+		ID:              "/",
+		Name:            "AZ Stocker",
+		ShortName:       "AZStocker",
+		Description:     "Arizona fish stocking schedule",
+		StartURL:        "/",
+		Scope:           "/",
+		Display:         "standalone",
+		Orientation:     "portrait",
+		BackgroundColor: backgroundColor,
+		ThemeColor:      themeColor,
+		Icons:           manifestIcons,
+		Shortcuts: []manifestShortcut{
+			{Name: "Community Fishing Program", ShortName: "CFP", URL: "/cfp", Icons: shortcutIcons},
+			{Name: "Winter", ShortName: "Winter", URL: "/winter", Icons: shortcutIcons},
+			{Name: "Spring & Summer", ShortName: "Spring", URL: "/springsummer", Icons: shortcutIcons},
+		},
+	}
+
+	favoritesURL := favoritesFromCookie(r)
+	if favoritesURL != "" {
+		manifest.Shortcuts = append(manifest.Shortcuts, manifestShortcut{
+			Name:      "Favorites",
+			ShortName: "Favorites",
+			URL:       favoritesURL,
+			Icons:     shortcutIcons,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/manifest+json")
+	err := json.NewEncoder(w).Encode(manifest)
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to write manifest", "err", err.Error())
+	}
+}
+
+// setFavoritesCookie saves the program and waters so they can be added to the manifest shortcuts
+func setFavoritesCookie(w http.ResponseWriter, program azstocker.Program, waters []string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     favoritesCookie,
+		Value:    url.QueryEscape(favoritesURL(program, waters)),
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// favoritesFromCookie reads and validates the favorites URL from the cookie. It is rebuilt from the
+// parsed values so only a program page can be used
+func favoritesFromCookie(r *http.Request) string {
+	cookie, err := r.Cookie(favoritesCookie)
+	if err != nil {
+		return ""
+	}
+
+	rawURL, err := url.QueryUnescape(cookie.Value)
+	if err != nil {
+		return ""
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	program, err := azstocker.ParseProgram(strings.TrimPrefix(parsed.Path, "/"))
+	if err != nil {
+		return ""
+	}
+
+	waters := strings.Split(parsed.Query().Get(watersQueryParam), ",")
+	return favoritesURL(program, waters)
+}
+
+func favoritesURL(program azstocker.Program, waters []string) string {
+	query := url.Values{watersQueryParam: []string{strings.Join(waters, ",")}}
+	return "/" + string(program) + "?" + query.Encode()
+}
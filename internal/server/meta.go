@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+)
+
+const (
+	azgfdName = "Arizona Game & Fish Department"
+	azgfdURL  = "https://www.azgfd.com"
+)
+
+// pageMeta is used for the page title, Open Graph and Twitter tags, and structured data. Pages without
+// it use the defaults from the site catalog
+type pageMeta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	JSONLD      template.JS
+}
+
+// jsonLDEvent is a schema.org Event for a scheduled stocking
+type jsonLDEvent struct {
+	Context             string       `json:"@context"`
+	Type                string       `json:"@type"`
+	Name                string       `json:"name"`
+	Description         string       `json:"description"`
+	StartDate           string       `json:"startDate"`
+	EndDate             string       `json:"endDate"`
+	EventStatus         string       `json:"eventStatus"`
+	EventAttendanceMode string       `json:"eventAttendanceMode"`
+	URL                 string       `json:"url"`
+	Location            jsonLDPlace  `json:"location"`
+	Organizer           jsonLDEntity `json:"organizer"`
+	IsAccessibleForFree bool         `json:"isAccessibleForFree"`
+}
+
+type jsonLDPlace struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Address jsonLDAddress `json:"address"`
+}
+
+type jsonLDAddress struct {
+	Type           string `json:"@type"`
+	AddressRegion  string `json:"addressRegion"`
+	AddressCountry string `json:"addressCountry"`
+}
+
+type jsonLDEntity struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// waterMeta creates the summary and structured data for a page showing a single water
+func (s *server) waterMeta(lang i18n.Language, program azstocker.Program, calendar azstocker.Calendar) (pageMeta, error) {
+	pageURL := s.waterURL(program, calendar.WaterName)
+	meta := pageMeta{
+		Title:       lang.T("meta.title", calendar.WaterName),
+		Description: waterSummary(lang, calendar),
+		URL:         pageURL,
+		Image:       s.ogImageURL(lang, program, calendar.WaterName),
+	}
+
+	weekLang := azstocker.Language(lang)
+	events := []jsonLDEvent{}
+	for _, week := range upcomingStockings(calendar) {
+		start := week.Time()
+		events = append(events, jsonLDEvent{
+			Context:             "https://schema.org",
+			Type:                "Event",
+			Name:                lang.T("meta.eventName", week.Stock.NameIn(weekLang), calendar.WaterName),
+			Description:         lang.T("program." + string(program)),
+			StartDate:           start.Format(time.DateOnly),
+			EndDate:             week.End().Format(time.DateOnly),
+			EventStatus:         "https://schema.org/EventScheduled",
+			EventAttendanceMode: "https://schema.org/OfflineEventAttendanceMode",
+			URL:                 pageURL,
+			Location: jsonLDPlace{
+				Type:    "Place",
+				Name:    calendar.WaterName,
+				Address: jsonLDAddress{Type: "PostalAddress", AddressRegion: "AZ", AddressCountry: "US"},
+			},
+			Organizer:           jsonLDEntity{Type: "Organization", Name: azgfdName, URL: azgfdURL},
+			IsAccessibleForFree: true,
+		})
+	}
+	if len(events) == 0 {
+		return meta, nil
+	}
+
+	// json.Marshal escapes <, >, and & so it is safe to use in a script tag
+	data, err := json.Marshal(events)
+	if err != nil {
+		return pageMeta{}, fmt.Errorf("error encoding structured data: %w", err)
+	}
+	meta.JSONLD = template.JS(data)
+
+	return meta, nil
+}
+
+// waterSummary describes the next and last stocking, like "Next stocking: Trout 3 days from now"
+func waterSummary(lang i18n.Language, calendar azstocker.Calendar) string {
+	weekLang := azstocker.Language(lang)
+	summary := lang.T("meta.noNext")
+	next := calendar.Next()
+	if next.Year != 0 {
+		summary = lang.T("meta.next", next.Stock.NameIn(weekLang), calendar.HumanTimeIn(weekLang, next))
+	}
+
+	last := calendar.Last()
+	if last.Year != 0 && last.Stock != azstocker.UnknownFish && last.Status != azstocker.Cancelled {
+		summary += " " + lang.T("meta.last", last.Stock.NameIn(weekLang), calendar.HumanTimeIn(weekLang, last))
+	}
+	return summary
+}
+
+// upcomingStockings returns the known stockings starting with Next
+func upcomingStockings(calendar azstocker.Calendar) []azstocker.Week {
+	next := calendar.Next()
+	if next.Year == 0 {
+		return nil
+	}
+
+	result := []azstocker.Week{}
+	for _, week := range calendar.Data {
+		if week.Stock == azstocker.NoneFish || week.Stock == azstocker.UnknownFish {
+			continue
+		}
+		if !week.Time().Before(next.Time()) {
+			result = append(result, week)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaterMeta(t *testing.T) {
+	now := time.Now()
+	weekAt := func(t time.Time, stock azstocker.Fish) azstocker.Week {
+		return azstocker.Week{Year: t.Year(), Month: t.Month(), Day: t.Day(), Stock: stock}
+	}
+
+	calendar := azstocker.Calendar{
+		WaterName: "LYNX <LAKE>",
+		Data: []azstocker.Week{
+			weekAt(now.AddDate(0, 0, -16), azstocker.Catfish),
+			weekAt(now.AddDate(0, 0, 10), azstocker.Trout),
+			weekAt(now.AddDate(0, 0, 17), azstocker.NoneFish),
+			weekAt(now.AddDate(0, 0, 24), azstocker.Trout),
+		},
+	}
+
+	s := &server{urlBase: "https://azstocker.com"}
+	meta, err := s.waterMeta(i18n.English, azstocker.WinterProgram, calendar)
+	assert.NoError(t, err)
+	assert.Equal(t, "LYNX <LAKE> Fish Stocking Schedule", meta.Title)
+	assert.Equal(t, "Next stocking: Trout 1 week from now. Last stocked: Catfish 2 weeks ago.", meta.Description)
+	assert.Equal(t, "https://azstocker.com/winter?waters=LYNX+%3CLAKE%3E", meta.URL)
+
+	var events []jsonLDEvent
+	assert.NoError(t, json.Unmarshal([]byte(meta.JSONLD), &events))
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Event", events[0].Type)
+	assert.Equal(t, "Trout stocking at LYNX <LAKE>", events[0].Name)
+	assert.Equal(t, now.AddDate(0, 0, 10).Format(time.DateOnly), events[0].StartDate)
+
+	t.Run("Template", func(t *testing.T) {
+		tmpl, err := loadTemplates()
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		err = executeTemplate(context.Background(), tmpl, &out, "calendar", map[string]any{
+			"lang":      azstocker.English,
+			"program":   azstocker.WinterProgram,
+			"calendar":  azstocker.StockingData{calendar},
+			"waters":    calendar.WaterName,
+			"numWaters": 1,
+			"meta":      &meta,
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), `<meta property="og:description" content="Next stocking: Trout 1 week from now. Last stocked: Catfish 2 weeks ago.">`)
+		assert.Contains(t, out.String(), `<script type="application/ld+json">[{"@context":"https://schema.org","@type":"Event","name":"Trout stocking at LYNX \u003cLAKE\u003e"`)
+	})
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/transport"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/slok/go-http-metrics/metrics"
+	prommetrics "github.com/slok/go-http-metrics/metrics/prometheus"
+	metrics_middleware "github.com/slok/go-http-metrics/middleware"
+	"github.com/slok/go-http-metrics/middleware/std"
+)
+
+const defaultMetricsPath = "/metrics"
+
+// MetricsConfig controls how the Prometheus metrics endpoint is exposed
+type MetricsConfig struct {
+	// Addr is the address for a separate metrics listener. The metrics endpoint is disabled if it is empty and
+	// Mount is not set
+	Addr string
+	// Path is where the metrics handler is served. It defaults to /metrics
+	Path string
+	// Mount serves metrics on the main listener under Path instead of a separate listener
+	Mount bool
+
+	// Username and Password enable basic auth for the metrics endpoint
+	Username string
+	Password string
+	// BearerToken enables bearer token auth for the metrics endpoint
+	BearerToken string
+}
+
+// DefaultMetricsConfig serves metrics on a separate listener, which is used by Fly
+var DefaultMetricsConfig = MetricsConfig{
+	Addr: "0.0.0.0:9091",
+	Path: defaultMetricsPath,
+}
+
+func WithMetrics(cfg MetricsConfig) Option {
+	return func(s *server) error {
+		s.metrics = cfg
+		return nil
+	}
+}
+
+func (c MetricsConfig) path() string {
+	if c.Path == "" {
+		return defaultMetricsPath
+	}
+	return "/" + strings.TrimPrefix(c.Path, "/")
+}
+
+func (c MetricsConfig) separateListener() bool {
+	return c.Addr != "" && !c.Mount
+}
+
+// metricsMux creates the handler for the separate metrics listener, which also serves health routes
+func (s *server) metricsMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+s.metrics.path(), s.metricsHandler())
+	s.registerHealthRoutes(mux)
+	return mux
+}
+
+// metricsHandler serves Prometheus metrics and requires auth if it is configured
+func (s *server) metricsHandler() http.Handler {
+	next := promhttp.Handler()
+	if s.metrics.Username == "" && s.metrics.BearerToken == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.metrics.authorized(r) {
+			if s.metrics.Username != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized checks the request for valid basic auth or bearer token credentials
+func (c MetricsConfig) authorized(r *http.Request) bool {
+	if c.BearerToken != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && secureCompare(token, c.BearerToken) {
+			return true
+		}
+	}
+
+	if c.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if ok && secureCompare(username, c.Username) && secureCompare(password, c.Password) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// httpMetricsRecorder is only created once since it registers its metrics with the default registry
+var httpMetricsRecorder = sync.OnceValue(func() metrics.Recorder {
+	return prommetrics.NewRecorder(prommetrics.Config{Prefix: "azstocker"})
+})
+
+func newMetricsMiddleware() func(http.Handler) http.Handler {
+	return std.HandlerProvider("", metrics_middleware.New(metrics_middleware.Config{
+		Recorder: httpMetricsRecorder(),
+	}))
+}
+
+var (
+	programRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "azstocker",
+		Name:      "program_requests_total",
+		Help:      "count of requests for each program",
+	}, []string{"program"})
+
+	// waterRequests only uses water names from the parsed StockingData so user input
+	// cannot create new label values
+	waterRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "azstocker",
+		Name:      "water_requests_total",
+		Help:      "count of requests for each known water",
+	}, []string{"program", "water"})
+
+	parseFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "azstocker",
+		Name:      "parse_failures_total",
+		Help:      "count of water rows that could not be parsed",
+	}, []string{"program"})
+
+	dataLastFetch = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "azstocker",
+		Name:      "data_last_fetch_timestamp_seconds",
+		Help:      "unix timestamp of the last successful fetch of stocking data",
+	}, []string{"program"})
+
+	programWaters = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "azstocker",
+		Name:      "program_waters",
+		Help:      "number of water rows parsed from the latest full fetch of a program",
+	}, []string{"program"})
+)
+
+func init() {
+	prometheus.MustRegister(programRequests, waterRequests, parseFailures, dataLastFetch, programWaters)
+}
+
+// getStockingData wraps azstocker.GetContext to record metrics and status about the fetched data. Options like
+// azstocker.WithSeason get a different Season, so changes are only recorded for the default Season. The number
+// of waters and parse warnings are also only recorded when the data is not filtered by waters
+func (s *server) getStockingData(ctx context.Context, program azstocker.Program, waters []string, seasonOpts ...azstocker.Option) (azstocker.StockingData, error) {
+	programLabel := string(program)
+
+	parseWarnings := 0
+	var season azstocker.Season
+	opts := append(s.sourceOptions(), azstocker.WithParseErrorHandler(func(waterName string, err error) {
+		parseWarnings++
+		parseFailures.WithLabelValues(programLabel).Inc()
+		slog.Log(ctx, slog.LevelWarn, "failed to parse water row", "program", programLabel, "water", waterName, "err", err.Error())
+	}), azstocker.WithSeasonHandler(func(chosen azstocker.Season) {
+		season = chosen
+	}))
+	fetchCtx := transport.TrackUpstream(ctx)
+	stockingData, err := azstocker.GetContext(fetchCtx, s.srv, program, waters, append(opts, seasonOpts...)...)
+	// the schedule was fetched successfully even if it doesn't have the requested waters
+	if errors.Is(err, azstocker.ErrNoMatchingWaters) {
+		s.recordFetch(program, -1, -1, s.fetchedAt(fetchCtx), nil)
+		return nil, err
+	}
+	if err != nil {
+		s.recordFetch(program, -1, -1, time.Time{}, err)
+		return nil, err
+	}
+
+	// responses from the HTTP cache are not new data. Schedule files are read directly, so they are always new
+	if s.spreadsheet != nil || transport.FromUpstream(fetchCtx) {
+		dataLastFetch.WithLabelValues(programLabel).Set(float64(time.Now().Unix()))
+	}
+	numWaters := -1
+	if len(waters) == 0 && len(seasonOpts) == 0 {
+		numWaters = len(stockingData)
+		programWaters.WithLabelValues(programLabel).Set(float64(numWaters))
+	} else {
+		parseWarnings = -1
+	}
+	s.recordFetch(program, numWaters, parseWarnings, s.fetchedAt(fetchCtx), nil)
+	if len(seasonOpts) == 0 {
+		err = s.changes.record(program, season.Name, len(waters) == 0, stockingData, time.Now())
+		if err != nil {
+			slog.Log(ctx, slog.LevelError, "failed to record schedule changes", "program", programLabel, "err", err.Error())
+		}
+	}
+
+	if reports := s.reports.get(ctx); len(reports) > 0 {
+		stockingData = stockingData.Reconcile(reports...)
+	}
+
+	return stockingData, nil
+}
+
+// fetchedAt is when the data for a context from transport.TrackUpstream was fetched from upstream. Schedule
+// files are read directly, so they are always new
+func (s *server) fetchedAt(ctx context.Context) time.Time {
+	if s.spreadsheet != nil {
+		return time.Now()
+	}
+	fetchedAt, _ := transport.FetchedAt(ctx)
+	return fetchedAt
+}
+
+// sourceOptions chooses where the schedules are read from
+func (s *server) sourceOptions() []azstocker.Option {
+	if s.spreadsheet == nil {
+		return []azstocker.Option{}
+	}
+	return []azstocker.Option{azstocker.WithSpreadsheet(s.spreadsheet)}
+}
+
+// recordWaterRequests increments the request counter for waters that exist in the data
+func recordWaterRequests(program azstocker.Program, stockingData azstocker.StockingData) {
+	for _, calendar := range stockingData {
+		waterRequests.WithLabelValues(string(program), calendar.WaterName).Inc()
+	}
+}
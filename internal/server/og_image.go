@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/card"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+)
+
+const (
+	// ogImageCacheSize is the max number of rendered images that are kept in memory
+	ogImageCacheSize = 256
+
+	ogImageMaxAge = time.Hour
+)
+
+// ogImageCache keeps rendered images by their version so images are only rendered again when the data
+// changes. The oldest image is removed when it is full
+type ogImageCache struct {
+	mu     sync.Mutex
+	images map[string][]byte
+	order  []string
+}
+
+func (c *ogImageCache) get(version string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	img, ok := c.images[version]
+	return img, ok
+}
+
+func (c *ogImageCache) set(version string, img []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.images == nil {
+		c.images = map[string][]byte{}
+	}
+	if _, ok := c.images[version]; ok {
+		return
+	}
+
+	if len(c.order) >= ogImageCacheSize {
+		delete(c.images, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.images[version] = img
+	c.order = append(c.order, version)
+}
+
+// ogImage responds with a PNG preview of a single water's last and next stocking
+func (s *server) ogImage(program azstocker.Program) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		waters := query{r}.StringSlice(watersQueryParam)
+		if len(waters) != 1 {
+			http.Error(w, "exactly one water is required", http.StatusBadRequest)
+			return
+		}
+		// ogImageURL sets the lang query parameter, so the cached image only depends on the URL
+		lang := queryLanguage(r)
+
+		stockingData, err := s.getStockingData(r.Context(), program, waters)
+		if err != nil {
+			s.dataErrorText(w, r, err)
+			return
+		}
+		if len(stockingData) != 1 {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		calendar := stockingData[0]
+
+		version := ogImageVersion(lang, program, calendar)
+		etag := `"` + version + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ogImageMaxAge.Seconds())))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		img, ok := s.ogImages.get(version)
+		if !ok {
+			var buf bytes.Buffer
+			err = card.Render(&buf, s.waterCard(lang, program, calendar))
+			if err != nil {
+				slog.Log(r.Context(), slog.LevelError, "failed to render image", "err", err.Error())
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			img = buf.Bytes()
+			s.ogImages.set(version, img)
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(img)
+	}
+}
+
+// ogImageVersion changes when the water's data changes. It also includes the date at the water since the
+// image shows relative times to stocking dates, which start at midnight there
+func ogImageVersion(lang i18n.Language, program azstocker.Program, calendar azstocker.Calendar) string {
+	today := time.Now().In(calendar.Location()).Format(time.DateOnly)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%v", lang, program, today, calendar.WaterName, calendar.Data)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func (s *server) waterCard(lang i18n.Language, program azstocker.Program, calendar azstocker.Calendar) card.Card {
+	footer := "azstocker.com"
+	parsed, err := url.Parse(s.urlBase)
+	if err == nil && parsed.Host != "" {
+		footer = parsed.Host
+	}
+
+	weekLang := azstocker.Language(lang)
+	stockingLine := func(labelKey, emptyKey string, week azstocker.Week) card.Line {
+		if week.Year == 0 {
+			return card.Line{Label: lang.T(labelKey), Value: lang.T(emptyKey)}
+		}
+		return card.Line{
+			Label: fmt.Sprintf("%s · %s", lang.T(labelKey), week.DateIn(weekLang)),
+			Value: fmt.Sprintf("%s · %s", week.Stock.NameIn(weekLang), calendar.HumanTimeIn(weekLang, week)),
+		}
+	}
+
+	return card.Card{
+		Title:    calendar.WaterName,
+		Subtitle: lang.T("program." + string(program)),
+		Lines: []card.Line{
+			stockingLine("calendar.lastStocked", "week.noData", calendar.Last()),
+			stockingLine("calendar.nextStocking", "meta.noNext", calendar.Next()),
+		},
+		Footer: footer,
+	}
+}
+
+// ogImageURL is the absolute URL of the water's preview image
+func (s *server) ogImageURL(lang i18n.Language, program azstocker.Program, water string) string {
+	query := url.Values{watersQueryParam: []string{water}}
+	if lang != i18n.Default {
+		query.Set(langQueryParam, string(lang))
+	}
+	return fmt.Sprintf("%s/%s/og.png?%s", s.urlBase, program, query.Encode())
+}
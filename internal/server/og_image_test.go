@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/card"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaterCard(t *testing.T) {
+	now := time.Now()
+	weekAt := func(t time.Time, stock azstocker.Fish) azstocker.Week {
+		return azstocker.Week{Year: t.Year(), Month: t.Month(), Day: t.Day(), Stock: stock}
+	}
+	last := now.AddDate(0, 0, -16)
+
+	calendar := azstocker.Calendar{
+		WaterName: "LYNX LAKE",
+		Data:      []azstocker.Week{weekAt(last, azstocker.Catfish)},
+	}
+
+	s := &server{urlBase: "https://example.com"}
+	c := s.waterCard(i18n.English, azstocker.WinterProgram, calendar)
+	assert.Equal(t, card.Card{
+		Title:    "LYNX LAKE",
+		Subtitle: "Winter",
+		Lines: []card.Line{
+			{
+				Label: fmt.Sprintf("Last stocked · %d %s %d", last.Year(), last.Month(), last.Day()),
+				Value: "Catfish · 2 weeks ago",
+			},
+			{Label: "Next stocking", Value: "No upcoming stocking is scheduled."},
+		},
+		Footer: "example.com",
+	}, c)
+
+	assert.Equal(t, "https://example.com/winter/og.png?waters=LYNX+LAKE", s.ogImageURL(i18n.English, azstocker.WinterProgram, "LYNX LAKE"))
+	assert.Equal(t, "https://example.com/winter/og.png?lang=es&waters=LYNX+LAKE", s.ogImageURL(i18n.Spanish, azstocker.WinterProgram, "LYNX LAKE"))
+
+	t.Run("VersionChangesWithData", func(t *testing.T) {
+		v1 := ogImageVersion(i18n.English, azstocker.WinterProgram, calendar)
+		assert.Equal(t, v1, ogImageVersion(i18n.English, azstocker.WinterProgram, calendar))
+		assert.NotEqual(t, v1, ogImageVersion(i18n.Spanish, azstocker.WinterProgram, calendar))
+
+		calendar.Data = append(calendar.Data, weekAt(now.AddDate(0, 0, 5), azstocker.Trout))
+		assert.NotEqual(t, v1, ogImageVersion(i18n.English, azstocker.WinterProgram, calendar))
+	})
+}
+
+func TestOGImageCache(t *testing.T) {
+	var c ogImageCache
+	for i := range ogImageCacheSize + 1 {
+		c.set(fmt.Sprint(i), []byte(fmt.Sprint(i)))
+	}
+
+	_, ok := c.get("0")
+	assert.False(t, ok)
+
+	img, ok := c.get(fmt.Sprint(ogImageCacheSize))
+	assert.True(t, ok)
+	assert.Equal(t, []byte(fmt.Sprint(ogImageCacheSize)), img)
+	assert.Len(t, c.images, ogImageCacheSize)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const notifyTTL = 24 * time.Hour
+
+var rateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "azstocker",
+	Name:      "rate_limited_requests_total",
+	Help:      "count of requests rejected by the per-client rate limiter",
+}, []string{"route"})
+
+func init() {
+	prometheus.MustRegister(rateLimitedRequests)
+}
+
+// ClientRateLimit configures token-bucket limits that are applied to each client IP for each route
+type ClientRateLimit struct {
+	// PerMinute is the number of requests allowed per minute. If it is zero, requests are not limited
+	PerMinute float64
+	// Burst is the maximum number of requests that can be made at once
+	Burst int
+	// TTL is how long an idle client is remembered
+	TTL time.Duration
+}
+
+// DefaultClientRateLimit allows normal browsing while stopping clients from hammering the Sheets API
+var DefaultClientRateLimit = ClientRateLimit{
+	PerMinute: 60,
+	Burst:     20,
+	TTL:       10 * time.Minute,
+}
+
+func WithClientRateLimit(cfg ClientRateLimit) Option {
+	return func(s *server) error {
+		s.clientRateLimit = cfg
+		return nil
+	}
+}
+
+// WithTrustProxyHeaders enables reading the client IP from Fly-Client-IP and X-Forwarded-For headers. This
+// should only be used when the server is behind a proxy that sets these headers
+func WithTrustProxyHeaders(trust bool) Option {
+	return func(s *server) error {
+		s.trustProxyHeaders = trust
+		return nil
+	}
+}
+
+// clientLimiter keeps a token-bucket for each client and forgets clients that have been idle for the TTL
+type clientLimiter struct {
+	limit rate.Limit
+	burst int
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	clients   map[string]*clientEntry
+	lastSweep time.Time
+}
+
+type clientEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newClientLimiter(limit rate.Limit, burst int, ttl time.Duration) *clientLimiter {
+	if ttl <= 0 {
+		ttl = DefaultClientRateLimit.TTL
+	}
+	return &clientLimiter{
+		limit:     limit,
+		burst:     max(burst, 1),
+		ttl:       ttl,
+		clients:   map[string]*clientEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// allow consumes a token for the client and returns false if none are available. When rejected, it also
+// returns how long until the next token is available
+func (cl *clientLimiter) allow(client string) (bool, time.Duration) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	now := time.Now()
+	reservation := cl.get(client, now).ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+
+	reservation.CancelAt(now)
+	return false, delay
+}
+
+// available checks if a client has a token without consuming it
+func (cl *clientLimiter) available(client string) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := cl.clients[client]
+	if !ok || now.Sub(entry.lastSeen) > cl.ttl {
+		return true
+	}
+	return entry.limiter.TokensAt(now) >= 1
+}
+
+// get must be called while holding the lock
+func (cl *clientLimiter) get(client string, now time.Time) *rate.Limiter {
+	cl.sweep(now)
+
+	entry, ok := cl.clients[client]
+	if !ok {
+		entry = &clientEntry{limiter: rate.NewLimiter(cl.limit, cl.burst)}
+		cl.clients[client] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter
+}
+
+// sweep removes idle clients. It runs at most once per TTL and must be called while holding the lock
+func (cl *clientLimiter) sweep(now time.Time) {
+	if now.Sub(cl.lastSweep) < cl.ttl {
+		return
+	}
+	cl.lastSweep = now
+
+	for client, entry := range cl.clients {
+		if now.Sub(entry.lastSeen) > cl.ttl {
+			delete(cl.clients, client)
+		}
+	}
+}
+
+// limitRequests wraps the handler with a per-client rate limit for the route
+func (s *server) limitRequests(route string, next http.HandlerFunc) http.HandlerFunc {
+	if s.clientRateLimit.PerMinute <= 0 {
+		return next
+	}
+
+	limiter := newClientLimiter(rate.Limit(s.clientRateLimit.PerMinute/60), s.clientRateLimit.Burst, s.clientRateLimit.TTL)
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := s.clientIP(r)
+		allowed, retryAfter := limiter.allow(client)
+		if !allowed {
+			rateLimitedRequests.WithLabelValues(route).Inc()
+			slog.Log(r.Context(), slog.LevelWarn, "rate limited request", "route", route, "client", client)
+
+			w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP gets the client's IP address. Proxy headers are only used if they are trusted
+func (s *server) clientIP(r *http.Request) string {
+	if s.trustProxyHeaders {
+		if ip := strings.TrimSpace(r.Header.Get("Fly-Client-IP")); ip != "" {
+			return ip
+		}
+
+		// clients can send their own X-Forwarded-For, so only the last address, which is added by the trusted
+		// proxy, is used
+		forwardedFor := r.Header.Values("X-Forwarded-For")
+		if len(forwardedFor) > 0 {
+			addresses := strings.Split(forwardedFor[len(forwardedFor)-1], ",")
+			if ip := strings.TrimSpace(addresses[len(addresses)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestClientLimiter(t *testing.T) {
+	limiter := newClientLimiter(rate.Every(time.Hour), 2, time.Hour)
+
+	allowed, _ := limiter.allow("1.1.1.1")
+	assert.True(t, allowed)
+	assert.True(t, limiter.available("1.1.1.1"))
+
+	allowed, _ = limiter.allow("1.1.1.1")
+	assert.True(t, allowed)
+	assert.False(t, limiter.available("1.1.1.1"))
+
+	allowed, retryAfter := limiter.allow("1.1.1.1")
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, 59*time.Minute)
+
+	allowed, _ = limiter.allow("2.2.2.2")
+	assert.True(t, allowed, "other clients are limited separately")
+}
+
+func TestClientLimiterExpires(t *testing.T) {
+	limiter := newClientLimiter(rate.Every(time.Hour), 1, time.Millisecond)
+
+	allowed, _ := limiter.allow("1.1.1.1")
+	assert.True(t, allowed)
+
+	time.Sleep(2 * time.Millisecond)
+	assert.True(t, limiter.available("1.1.1.1"))
+
+	allowed, _ = limiter.allow("2.2.2.2")
+	assert.True(t, allowed)
+	assert.Len(t, limiter.clients, 1, "idle client should be removed")
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		trust    bool
+		headers  map[string]string
+		expected string
+	}{
+		{"RemoteAddr", false, nil, "192.0.2.1"},
+		{"UntrustedHeaders", false, map[string]string{"Fly-Client-IP": "1.1.1.1"}, "192.0.2.1"},
+		{"FlyClientIP", true, map[string]string{"Fly-Client-IP": "1.1.1.1", "X-Forwarded-For": "2.2.2.2"}, "1.1.1.1"},
+		{"XForwardedFor", true, map[string]string{"X-Forwarded-For": "2.2.2.2"}, "2.2.2.2"},
+		{"SpoofedXForwardedFor", true, map[string]string{"X-Forwarded-For": "2.2.2.2, 3.3.3.3"}, "3.3.3.3"},
+		{"NoHeaders", true, nil, "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{trustProxyHeaders: tt.trust}
+			r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.expected, s.clientIP(r))
+		})
+	}
+}
+
+func TestLimitRequests(t *testing.T) {
+	s := &server{clientRateLimit: ClientRateLimit{PerMinute: 1, Burst: 1}}
+	handler := s.limitRequests("test", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+const (
+	// reportMaxAge is how long the stocking reports are used before they are loaded again
+	reportMaxAge = time.Hour
+	// reportLoadTimeout limits how long loading all of the reports can take
+	reportLoadTimeout = time.Minute
+)
+
+// WithStockingReports loads AZGFD stocking reports from files or URLs to show if scheduled stockings
+// actually happened
+func WithStockingReports(sources ...string) Option {
+	return func(s *server) error {
+		s.reports.sources = sources
+		return nil
+	}
+}
+
+// reportCache keeps the loaded reports. They are loaded in the background so a slow report never holds up
+// a request. If a report fails to load, the previous version is used
+type reportCache struct {
+	sources []string
+
+	mu      sync.Mutex
+	reports map[string]azstocker.Report
+	loaded  time.Time
+	loading bool
+}
+
+// get returns the reports that are already loaded and starts loading them again if they are old
+func (c *reportCache) get(ctx context.Context) []azstocker.Report {
+	if len(c.sources) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loading && time.Since(c.loaded) > reportMaxAge {
+		c.loading = true
+		go c.load(context.WithoutCancel(ctx))
+	}
+
+	result := []azstocker.Report{}
+	for _, source := range c.sources {
+		report, ok := c.reports[source]
+		if ok {
+			result = append(result, report)
+		}
+	}
+	return result
+}
+
+// load gets all of the reports without holding the lock
+func (c *reportCache) load(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, reportLoadTimeout)
+	defer cancel()
+
+	reports := map[string]azstocker.Report{}
+	for _, source := range c.sources {
+		report, err := azstocker.LoadReport(ctx, source)
+		if err != nil {
+			slog.Log(ctx, slog.LevelError, "failed to load stocking report", "source", source, "err", err.Error())
+			continue
+		}
+		reports[source] = report
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reports == nil {
+		c.reports = map[string]azstocker.Report{}
+	}
+	for source, report := range reports {
+		c.reports[source] = report
+	}
+	c.loaded = time.Now()
+	c.loading = false
+}
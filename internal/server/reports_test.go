@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportCache(t *testing.T) {
+	var available atomic.Bool
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		<-release
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("Date,Water\n10/14/2024,Kiwanis Lake\n"))
+	}))
+	defer srv.Close()
+	available.Store(true)
+
+	t.Run("NoSources", func(t *testing.T) {
+		var c reportCache
+		assert.Nil(t, c.get(context.Background()))
+	})
+
+	loaded := func(c *reportCache) func() bool {
+		return func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return !c.loading
+		}
+	}
+
+	c := &reportCache{sources: []string{srv.URL}}
+
+	// a slow report does not hold up requests
+	assert.Empty(t, c.get(context.Background()))
+	assert.Empty(t, c.get(context.Background()))
+	close(release)
+	assert.Eventually(t, loaded(c), time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), requests.Load())
+
+	reports := c.get(context.Background())
+	assert.Len(t, reports, 1)
+
+	// reports are not loaded again until they are old
+	assert.Equal(t, reports, c.get(context.Background()))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// the previous report is used when loading fails
+	available.Store(false)
+	c.mu.Lock()
+	c.loaded = time.Now().Add(-2 * reportMaxAge)
+	c.mu.Unlock()
+	assert.Equal(t, reports, c.get(context.Background()))
+	assert.Eventually(t, loaded(c), time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, reports, c.get(context.Background()))
+}
+
+func TestLastStockedKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		week     azstocker.Week
+		expected string
+	}{
+		{"Stocked", azstocker.Week{Stock: azstocker.Trout}, "feed.stocked"},
+		{"Completed", azstocker.Week{Stock: azstocker.Trout, Status: azstocker.Completed}, "feed.stocked"},
+		{"CompletedUnknown", azstocker.Week{Stock: azstocker.UnknownFish, Status: azstocker.Completed}, "feed.completed"},
+		{"Unknown", azstocker.Week{Stock: azstocker.UnknownFish}, "feed.unconfirmed"},
+		{"Unconfirmed", azstocker.Week{Stock: azstocker.Trout, Status: azstocker.Unconfirmed}, "feed.unconfirmed"},
+		{"Cancelled", azstocker.Week{Stock: azstocker.Trout, Status: azstocker.Cancelled}, "feed.cancelled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lastStockedKey(tt.week))
+		})
+	}
+}
@@ -3,25 +3,30 @@ package server
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/calvinmclean/azstocker"
-
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	prommetrics "github.com/slok/go-http-metrics/metrics/prometheus"
-	metrics_middleware "github.com/slok/go-http-metrics/middleware"
-	"github.com/slok/go-http-metrics/middleware/std"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"github.com/calvinmclean/azstocker/internal/static"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 	"google.golang.org/api/sheets/v4"
 )
 
@@ -29,28 +34,10 @@ const (
 	watersQueryParam = "waters"
 	templateFilename = "templates/*"
 
-	metricsAddr = "0.0.0.0:9091"
-
 	internalErrorMessage = "Internal Server Error. We are looking into the issue. Please try again later."
 )
 
-var (
-	programsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "azstocker",
-		Name:      "program_requests",
-		Help:      "gauge of programs requested",
-	}, []string{"program"})
-
-	watersGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "azstocker",
-		Name:      "water_requests",
-		Help:      "gauge of waters requested",
-	}, []string{"water"})
-)
-
-func init() {
-	prometheus.MustRegister(programsGauge, watersGauge)
-}
+var tracer = otel.Tracer("github.com/calvinmclean/azstocker/internal/server")
 
 //go:embed templates/*
 var templateFS embed.FS
@@ -64,66 +51,199 @@ func WithPushoverClient(appToken, recipientToken string) Option {
 			return fmt.Errorf("error initializing notify client: %w", err)
 		}
 		s.nc = nc
-		s.notifySourceIPs = &sync.Map{}
+		s.notifyLimiter = newClientLimiter(rate.Every(notifyTTL), 1, notifyTTL)
 		return nil
 	}
 }
 
-func RunServer(addr string, srv *sheets.Service, urlBase string, opts ...Option) error {
-	handler, err := newServer(srv, urlBase, opts...)
-	if err != nil {
-		return err
-	}
+// Timeouts configures the HTTP servers and how long to wait for in-flight requests during shutdown
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+	Shutdown   time.Duration
+}
+
+// DefaultTimeouts allow enough time for a request to wait on the Sheets API
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       10 * time.Second,
+	Write:      30 * time.Second,
+	Idle:       120 * time.Second,
+	Shutdown:   10 * time.Second,
+}
 
-	metricServer, metricMiddleware := newMetricsServer()
-	go func() {
-		metricErr := http.ListenAndServe(metricsAddr, metricServer)
+// WithScheduleDir reads the schedules from CSV and XLSX files in the directory instead of Google Sheets. The
+// files are only read when the server starts
+func WithScheduleDir(dir string) Option {
+	return func(s *server) error {
+		if dir == "" {
+			return nil
+		}
+		spreadsheet, err := azstocker.OpenSpreadsheetDir(dir)
 		if err != nil {
-			slog.Log(context.Background(), slog.LevelError, "error running metrics server", "err", metricErr.Error())
+			return fmt.Errorf("error loading schedules: %w", err)
 		}
-	}()
-	return http.ListenAndServe(addr, metricMiddleware(handler))
+		s.spreadsheet = spreadsheet
+		return nil
+	}
 }
 
-func newMetricsServer() (*http.ServeMux, func(http.Handler) http.Handler) {
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *server) error {
+		s.timeouts = timeouts
+		return nil
+	}
+}
 
-	middleware := std.HandlerProvider("", metrics_middleware.New(metrics_middleware.Config{
-		Recorder: prommetrics.NewRecorder(prommetrics.Config{Prefix: "azstocker"}),
-	}))
+// RunServer runs the app and metrics servers until the context is cancelled or either server fails. When
+// stopping, it waits for in-flight requests to complete before returning
+func RunServer(ctx context.Context, addr string, srv *sheets.Service, urlBase string, opts ...Option) error {
+	s, err := newServer(srv, urlBase, append([]Option{WithTimeouts(DefaultTimeouts)}, opts...)...)
+	if err != nil {
+		return err
+	}
+	handler := s.handler()
+
+	httpServers := map[string]*http.Server{
+		"app": s.httpServer(addr, otelhttp.NewHandler(newMetricsMiddleware()(handler), "azstocker")),
+	}
+	if s.metrics.separateListener() {
+		httpServers["metrics"] = s.httpServer(s.metrics.Addr, s.metricsMux())
+	}
+
+	errs := make(chan error, len(httpServers))
+	for name, httpServer := range httpServers {
+		go func() {
+			err := httpServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("error running %s server: %w", name, err)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		slog.Log(ctx, slog.LevelInfo, "shutting down server")
+	case err = <-errs:
+		slog.Log(ctx, slog.LevelError, "shutting down after server error", "err", err.Error())
+	}
 
-	return mux, middleware
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.timeouts.Shutdown)
+	defer cancel()
+
+	shutdownErrs := []error{err}
+	for _, httpServer := range httpServers {
+		shutdownErrs = append(shutdownErrs, httpServer.Shutdown(shutdownCtx))
+	}
+	return errors.Join(shutdownErrs...)
 }
 
-func newServer(srv *sheets.Service, urlBase string, opts ...Option) (http.Handler, error) {
-	mux := http.NewServeMux()
+func (s *server) httpServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
+}
 
-	s := &server{srv, urlBase, nil, nil}
+func newServer(srv *sheets.Service, urlBase string, opts ...Option) (*server, error) {
+	s := &server{
+		srv:             srv,
+		urlBase:         urlBase,
+		metrics:         DefaultMetricsConfig,
+		clientRateLimit: DefaultClientRateLimit,
+		lists:           newMemoryListStore(),
+	}
 	for _, opt := range opts {
 		err := opt(s)
 		if err != nil {
 			return nil, err
 		}
 	}
-	mux.HandleFunc("/", s.homepage)
-	mux.HandleFunc("/index.html", s.homepage)
-	mux.HandleFunc("/sitemap.txt", s.sitemap)
+	return s, nil
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", s.limitRequests("homepage", s.homepage))
+	mux.HandleFunc("/index.html", s.limitRequests("homepage", s.homepage))
+	mux.HandleFunc("/sitemap.txt", s.limitRequests("sitemap", s.sitemap))
+	mux.HandleFunc("GET /sitemap.xml", s.limitRequests("sitemap", s.sitemapIndex))
+	mux.HandleFunc("GET /robots.txt", s.robots)
 	if s.nc != nil {
-		mux.HandleFunc("/notify", s.notify)
+		mux.HandleFunc("/notify", s.limitRequests("notify", s.notify))
 	}
 	mux.HandleFunc("/manifest.json", s.pwaManifest)
-	mux.HandleFunc("/{program}", s.errorHandler(s.getProgramSchedule))
+	mux.Handle("GET "+static.Prefix, static.Handler())
+	mux.Handle("GET /sw.js", static.ServiceWorker())
+	mux.HandleFunc("POST /l", s.limitRequests("list", s.createList))
+	mux.HandleFunc("GET /l/{id}", s.limitRequests("list", s.errorHandler(s.getListSchedule)))
+	mux.HandleFunc("GET /this-week", s.limitRequests("thisWeek", s.errorHandler(s.thisWeek)))
+	// feeds, sitemaps, and images are registered for each program since a wildcard would conflict with /static/
+	for _, p := range programs {
+		mux.HandleFunc("GET /sitemap-"+string(p)+".xml", s.limitRequests("sitemap", s.programSitemap(p)))
+		mux.HandleFunc("GET /"+string(p)+"/feed.atom", s.limitRequests("feed", s.errorHandler(s.programFeed(p))))
+		mux.HandleFunc("GET /"+string(p)+"/changes.atom", s.limitRequests("feed", s.errorHandler(s.changesFeed(p))))
+		mux.HandleFunc("GET /"+string(p)+"/og.png", s.limitRequests("image", s.errorHandler(s.ogImage(p))))
+		mux.HandleFunc("GET /export/"+string(p)+".csv", s.limitRequests("export", s.errorHandler(s.exportCSV(p))))
+	}
+	mux.HandleFunc("/{program}", s.limitRequests("program", s.errorHandler(s.getProgramSchedule)))
+	s.registerHealthRoutes(mux)
+	if s.metrics.Mount {
+		mux.Handle("GET "+s.metrics.path(), s.metricsHandler())
+	}
 
-	return mux, nil
+	return routeSpanName(mux)
 }
 
-type server struct {
-	srv     *sheets.Service
-	urlBase string
+// routeSpanName names the request's span after the matching route, like "GET /{program}". otelhttp starts
+// the span before the request is routed, so it doesn't know the pattern yet
+func routeSpanName(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		if pattern != "" {
+			// patterns like "GET /this-week" already have the method
+			_, route, hasMethod := strings.Cut(pattern, " ")
+			if !hasMethod {
+				route = pattern
+			}
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
 
-	nc              *notifyClient
-	notifySourceIPs *sync.Map
+type server struct {
+	srv         *sheets.Service
+	spreadsheet *azstocker.Spreadsheet
+	urlBase     string
+
+	nc            *notifyClient
+	notifyLimiter *clientLimiter
+	layoutAlerts  alertTracker
+
+	clientRateLimit   ClientRateLimit
+	trustProxyHeaders bool
+
+	lists listStore
+
+	timeouts    Timeouts
+	metrics     MetricsConfig
+	readyMaxAge time.Duration
+	statusMu    sync.Mutex
+	status      map[azstocker.Program]*programStatus
+	changes     changeTracker
+	sitemaps    sitemapCache
+	ogImages    ogImageCache
+	reports     reportCache
 }
 
 func (s *server) errorHandler(next http.HandlerFunc) http.HandlerFunc {
@@ -170,15 +290,6 @@ func (rr *responseRecorder) Write(b []byte) (int, error) {
 	return rr.ResponseWriter.Write(b)
 }
 
-// manifest.json enables PWA for mobile devices
-func (s *server) pwaManifest(w http.ResponseWriter, r *http.Request) {
-	_, _ = w.Write([]byte(`{
-	  "name": "AZ Stocker",
-	  "start_url": "/",
-	  "display": "standalone"
-	}`))
-}
-
 func (s *server) homepage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -191,49 +302,17 @@ func (s *server) homepage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = tmpl.ExecuteTemplate(w, "homepage", map[string]any{
+	err = executeTemplate(r.Context(), tmpl, w, "homepage", withLanguage(r, language(w, r), map[string]any{
 		"notifyEnabled": s.notifyEnabled(r),
 		"program":       "home",
-	})
+		"savedLists":    s.savedLists(r),
+	}))
 	if err != nil {
 		slog.Log(r.Context(), slog.LevelError, "failed to execute template", "err", err.Error())
 		return
 	}
 }
 
-func (s *server) sitemap(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	s.writeSitemap(r.Context(), w)
-}
-
-func (s *server) writeSitemap(ctx context.Context, w io.Writer) {
-	programs := []azstocker.Program{azstocker.CFProgram, azstocker.WinterProgram, azstocker.SpringSummerProgram}
-	for _, p := range programs {
-		stockingData, err := azstocker.Get(s.srv, p, []string{})
-		if err != nil {
-			slog.Log(ctx, slog.LevelError, "failed to get data", "err", err.Error())
-		}
-
-		stockingData.Sort(func(c1, c2 azstocker.Calendar) int {
-			return strings.Compare(c1.WaterName, c2.WaterName)
-		})
-
-		urlBase := fmt.Sprintf("%s/%s", s.urlBase, p)
-		fmt.Fprintf(w, "%s\n", urlBase)
-
-		for _, data := range stockingData {
-			query := url.Values{
-				"waters": []string{data.WaterName},
-			}
-			fmt.Fprintf(w, "%s?%s\n", urlBase, query.Encode())
-		}
-	}
-}
-
 func (s *server) getProgramSchedule(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -248,24 +327,32 @@ func (s *server) getProgramSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	programsGauge.WithLabelValues(programStr).Inc()
+	programRequests.WithLabelValues(string(program)).Inc()
 
 	q := query{r}
 	showAll := q.Bool("showAll")
 	sortBy := r.URL.Query().Get("sortBy")
 	waters := q.StringSlice("waters")
-	if len(waters) > 0 {
-		for _, w := range waters {
-			watersGauge.WithLabelValues(w).Inc()
-		}
+
+	filters, err := parseFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	stockingData, err := azstocker.Get(s.srv, program, waters)
+	stockingData, err := s.getStockingData(r.Context(), program, waters)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		slog.Log(r.Context(), slog.LevelError, "failed to get data", "err", err.Error())
+		s.dataErrorPage(w, r, err, waters)
 		return
 	}
+	if len(waters) > 0 {
+		recordWaterRequests(program, stockingData)
+		if q.Bool("favorites") {
+			setFavoritesCookie(w, program, waters)
+		}
+	}
+
+	stockingData = stockingData.Filter(filters...)
 
 	switch sortBy {
 	case "next":
@@ -283,16 +370,31 @@ func (s *server) getProgramSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	lang := language(w, r)
+	var meta *pageMeta
+	if len(waters) == 1 && len(stockingData) == 1 {
+		waterMeta, err := s.waterMeta(lang, program, stockingData[0])
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to create page metadata", "err", err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		meta = &waterMeta
+	}
+
 	watersStr := strings.Join(waters, ", ")
-	err = tmpl.ExecuteTemplate(w, "calendar", map[string]any{
+	err = executeTemplate(r.Context(), tmpl, w, "calendar", withLanguage(r, lang, map[string]any{
 		"showAll":       showAll,
 		"program":       program,
 		"calendar":      stockingData,
 		"waters":        watersStr,
 		"numWaters":     len(waters),
 		"sortedBy":      sortBy,
+		"feedURL":       feedURL(program, waters),
+		"meta":          meta,
 		"notifyEnabled": s.notifyEnabled(r),
-	})
+		"savedLists":    s.savedLists(r),
+	}))
 	if err != nil {
 		slog.Log(r.Context(), slog.LevelError, "failed to execute template", "err", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -306,15 +408,15 @@ func (s *server) notify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	remoteAddr := remoteAddr(r)
+	clientIP := s.clientIP(r)
 
-	_, exists := s.notifySourceIPs.LoadOrStore(remoteAddr, struct{}{})
-	if exists {
-		slog.Log(r.Context(), slog.LevelWarn, "notify from repeated IP", "remote_addr", remoteAddr)
+	allowed, _ := s.notifyLimiter.allow(clientIP)
+	if !allowed {
+		slog.Log(r.Context(), slog.LevelWarn, "notify from repeated IP", "client_ip", clientIP)
 		return
 	}
 
-	slog.Log(r.Context(), slog.LevelInfo, "received notify request", "remote_addr", remoteAddr)
+	slog.Log(r.Context(), slog.LevelInfo, "received notify request", "client_ip", clientIP)
 
 	err := s.nc.send("AZStocker", "AZStocker got a like!")
 	if err != nil {
@@ -328,17 +430,7 @@ func (s *server) notifyEnabled(r *http.Request) bool {
 		return false
 	}
 
-	_, alreadyNotified := s.notifySourceIPs.Load(remoteAddr(r))
-	return !alreadyNotified
-}
-
-func remoteAddr(r *http.Request) string {
-	remoteAddr := r.RemoteAddr
-	i := strings.LastIndex(r.RemoteAddr, ":")
-	if i > 0 {
-		remoteAddr = remoteAddr[0:i]
-	}
-	return remoteAddr
+	return s.notifyLimiter.available(s.clientIP(r))
 }
 
 type query struct {
@@ -351,22 +443,79 @@ func (q query) Bool(key string) bool {
 
 func (q query) StringSlice(key string) []string {
 	result := []string{}
-	if !q.r.URL.Query().Has(watersQueryParam) {
+	if !q.r.URL.Query().Has(key) {
 		return result
 	}
 
-	rawQuerySlice := strings.Split(q.r.URL.Query().Get(watersQueryParam), ",")
+	rawQuerySlice := strings.Split(q.r.URL.Query().Get(key), ",")
 	for _, w := range rawQuerySlice {
 		result = append(result, strings.TrimSpace(w))
 	}
 	return result
 }
 
+// Date parses a date like 2024-10-21. It is zero if the parameter is not set
+func (q query) Date(key string) (time.Time, error) {
+	value := q.r.URL.Query().Get(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := azstocker.ParseDate(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return date, nil
+}
+
+// executeTemplate renders the named template inside of a tracing span
+func executeTemplate(ctx context.Context, tmpl *template.Template, w io.Writer, name string, data any) error {
+	_, span := tracer.Start(ctx, "executeTemplate", trace.WithAttributes(attribute.String("template", name)))
+	defer span.End()
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
 func loadTemplates() (*template.Template, error) {
 	tmpl := template.New("template").Funcs(template.FuncMap{
 		"escapeSingleQuote": func(in string) string {
 			return strings.ReplaceAll(in, "'", "\\'")
 		},
+		"static": static.URL,
+		// templates use azstocker.Language so it can be passed to methods like Week.DateIn
+		"t": func(lang azstocker.Language, key string, args ...any) string {
+			return i18n.Language(lang).T(key, args...)
+		},
+		// tHTML is used for messages that contain markup. Messages are trusted but the args are escaped
+		"tHTML": func(lang azstocker.Language, key string, args ...any) template.HTML {
+			escaped := make([]any, len(args))
+			for i, arg := range args {
+				escaped[i] = template.HTMLEscapeString(fmt.Sprint(arg))
+			}
+			return template.HTML(i18n.Language(lang).T(key, escaped...))
+		},
+		"month": func(lang azstocker.Language, m time.Month) string {
+			return i18n.Language(lang).Month(m)
+		},
+		// dict builds a map from key/value pairs so multiple values can be passed to a template
+		"dict": func(pairs ...any) (map[string]any, error) {
+			if len(pairs)%2 != 0 {
+				return nil, errors.New("dict requires key/value pairs")
+			}
+			result := map[string]any{}
+			for i := 0; i < len(pairs); i += 2 {
+				key, ok := pairs[i].(string)
+				if !ok {
+					return nil, fmt.Errorf("dict key must be a string: %v", pairs[i])
+				}
+				result[key] = pairs[i+1]
+			}
+			return result, nil
+		},
 	})
 
 	if os.Getenv("DEV") == "true" {
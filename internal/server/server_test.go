@@ -3,20 +3,36 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/calvinmclean/azstocker"
+	"github.com/calvinmclean/azstocker/internal/i18n"
+	"github.com/calvinmclean/azstocker/internal/sheetstest"
 	"github.com/calvinmclean/azstocker/internal/transport"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
 )
 
-func TestWriteSitemap(t *testing.T) {
+func TestSitemap(t *testing.T) {
 	r, err := recorder.New(
 		"../testdata/fixtures/both",
 		recorder.WithSkipRequestLatency(true),
@@ -52,11 +68,17 @@ func TestWriteSitemap(t *testing.T) {
 		recorder.WithMode(recorder.ModeRecordOnly)(r)
 	}
 
-	cacheControl := transport.NewCacheControl(time.Minute, r)
+	cacheControl := transport.NewCacheControl(time.Minute, sheetstest.WithMetadata(r))
 	srv, err := azstocker.NewService(apiKey, cacheControl)
 	assert.NoError(t, err)
 
-	expected := `http://example.com/cfp
+	s := &server{srv: srv, urlBase: "http://example.com"}
+
+	tests := []struct {
+		program  azstocker.Program
+		expected string
+	}{
+		{azstocker.CFProgram, `http://example.com/cfp
 http://example.com/cfp?waters=Avondale+-+Alamar+Park+Pond
 http://example.com/cfp?waters=Avondale+-+Festival+Fields+Pond
 http://example.com/cfp?waters=Avondale+-+Friendship+Pond
@@ -107,8 +129,8 @@ http://example.com/cfp?waters=Tucson+-+Lakeside+Lake
 http://example.com/cfp?waters=Tucson+-+Silverbell+Lake
 http://example.com/cfp?waters=Yuma+-+Fortuna+Lake
 http://example.com/cfp?waters=Yuma+-+PAAC+Pond
-http://example.com/cfp?waters=Yuma+-+West+Wetlands+Pond
-http://example.com/winter
+http://example.com/cfp?waters=Yuma+-+West+Wetlands+Pond`},
+		{azstocker.WinterProgram, `http://example.com/winter
 http://example.com/winter?waters=ASHURST+LAKE
 http://example.com/winter?waters=BEAVER+CREEK+%28WET%29
 http://example.com/winter?waters=BENDER%27S+POND
@@ -138,7 +160,6 @@ http://example.com/winter?waters=PAAC+POND
 http://example.com/winter?waters=PARKER+%28LA+PAZ%29
 http://example.com/winter?waters=PARKER+CANYON
 http://example.com/winter?waters=PATAGONIA
-http://example.com/winter?waters=PATAGONIA
 http://example.com/winter?waters=PENA+BLANCA
 http://example.com/winter?waters=RAINBOW+LAKE
 http://example.com/winter?waters=REDONDO+LAKE
@@ -156,12 +177,629 @@ http://example.com/winter?waters=WEST+CLEAR+CREEK
 http://example.com/winter?waters=WEST+WETLANDS+POND
 http://example.com/winter?waters=WOODLAND+RESERVOIR
 http://example.com/winter?waters=YAVAPAI+LAKES
-http://example.com/winter?waters=https%3A%2F%2Fwww.azgfd.com%2F
-http://example.com/springsummer
-`
-
-	w := new(bytes.Buffer)
-	server := &server{srv: srv, urlBase: "http://example.com"}
-	server.writeSitemap(context.Background(), w)
-	assert.Equal(t, expected, string(w.String()))
+http://example.com/winter?waters=https%3A%2F%2Fwww.azgfd.com%2F`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.program), func(t *testing.T) {
+			urls, err := s.sitemapURLs(context.Background(), tt.program)
+			assert.NoError(t, err)
+
+			locs := []string{}
+			for _, u := range urls {
+				locs = append(locs, u.Loc)
+			}
+			assert.Equal(t, tt.expected, strings.Join(locs, "\n"))
+		})
+	}
+
+	t.Run("ProgramSitemapXML", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.programSitemap(azstocker.CFProgram)(w, httptest.NewRequest(http.MethodGet, "/sitemap-cfp.xml", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var urlSet urlSetXML
+		assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &urlSet))
+		assert.Equal(t, sitemapNamespace, urlSet.XMLNS)
+		assert.Equal(t, "http://example.com/cfp", urlSet.URLs[0].Loc)
+		assert.NotEmpty(t, urlSet.URLs[0].LastMod)
+
+		// the water's lastmod is when its schedule was first fetched since it has not changed
+		waterURL, err := url.Parse(urlSet.URLs[1].Loc)
+		assert.NoError(t, err)
+		lastMod, ok := s.changes.lastModified(azstocker.CFProgram, waterURL.Query().Get("waters"))
+		assert.True(t, ok)
+		assert.Equal(t, formatLastMod(lastMod), urlSet.URLs[1].LastMod)
+	})
+
+	t.Run("MissingProgram", func(t *testing.T) {
+		// the fixture does not include the spring/summer schedule so nothing should be written
+		w := new(bytes.Buffer)
+		err := s.writeSitemap(context.Background(), w)
+		assert.Error(t, err)
+		assert.Empty(t, w.String())
+	})
+}
+
+func TestWaterLastModified(t *testing.T) {
+	s, err := newServer(nil, "http://example.com")
+	assert.NoError(t, err)
+
+	week1 := azstocker.Week{Year: 2024, Month: time.October, Day: 21, Stock: azstocker.Trout}
+	week2 := azstocker.Week{Year: 2024, Month: time.October, Day: 28, Stock: azstocker.Trout}
+	first := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+		{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1}},
+	}, first))
+
+	t.Run("FirstFetch", func(t *testing.T) {
+		assert.Equal(t, first, s.waterLastModified(azstocker.WinterProgram, "LYNX LAKE"))
+	})
+
+	t.Run("UnchangedSchedule", func(t *testing.T) {
+		assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1}},
+		}, first.Add(24*time.Hour)))
+		assert.Equal(t, first, s.waterLastModified(azstocker.WinterProgram, "LYNX LAKE"))
+	})
+
+	t.Run("ChangedSchedule", func(t *testing.T) {
+		changed := first.Add(48 * time.Hour)
+		assert.NoError(t, s.changes.record(azstocker.WinterProgram, "2024-25 Winter", true, azstocker.StockingData{
+			{WaterName: "LYNX LAKE", Data: []azstocker.Week{week1, week2}},
+		}, changed))
+		assert.Equal(t, changed, s.waterLastModified(azstocker.WinterProgram, "LYNX LAKE"))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		assert.True(t, s.waterLastModified(azstocker.WinterProgram, "WATSON LAKE").IsZero())
+	})
+}
+
+func TestHealthRoutes(t *testing.T) {
+	s, err := newServer(nil, "http://example.com")
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	t.Run("Healthz", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", w.Body.String())
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		s.recordFetch(azstocker.CFProgram, 10, 1, time.Now().Add(-time.Hour), nil)
+		s.recordFetch(azstocker.WinterProgram, -1, 0, time.Time{}, errors.New("upstream error"))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result struct {
+			Programs map[azstocker.Program]programStatus `json:"programs"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Len(t, result.Programs, 3)
+		assert.Equal(t, 10, result.Programs[azstocker.CFProgram].Waters)
+		assert.Equal(t, 1, result.Programs[azstocker.CFProgram].ParseWarnings)
+		assert.NotNil(t, result.Programs[azstocker.CFProgram].LastSuccess)
+		assert.NotNil(t, result.Programs[azstocker.CFProgram].LastFetch)
+		assert.GreaterOrEqual(t, *result.Programs[azstocker.CFProgram].AgeSeconds, time.Hour.Seconds())
+		assert.Equal(t, "upstream error", result.Programs[azstocker.WinterProgram].LastError)
+		assert.Nil(t, result.Programs[azstocker.WinterProgram].LastSuccess)
+	})
+}
+
+func TestParseWarnings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"):
+			resp = sheets.ValueRange{Values: [][]any{{"OCTOBER"}, {"7"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			// BROKEN LAKE has more stock columns than dates, so it fails to parse
+			resp = sheets.ValueRange{Values: [][]any{
+				{"Prescott Area"},
+				{"  LYNX LAKE", "X"},
+				{"  BROKEN LAKE", "X", "X", "X", "X"},
+			}}
+		default:
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+				{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}},
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+
+	s, err := newServer(srv, "http://example.com")
+	assert.NoError(t, err)
+
+	_, err = s.getStockingData(context.Background(), azstocker.WinterProgram, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.statusSnapshot()[azstocker.WinterProgram].ParseWarnings)
+
+	// a filtered fetch does not parse the broken row, but the schedule still has it
+	_, err = s.getStockingData(context.Background(), azstocker.WinterProgram, []string{"lynx lake"})
+	assert.NoError(t, err)
+	status := s.statusSnapshot()[azstocker.WinterProgram]
+	assert.Equal(t, 1, status.ParseWarnings)
+	assert.Equal(t, 1, status.Waters)
+}
+
+func TestReadyzStaleData(t *testing.T) {
+	defer func(original []azstocker.Program) { programs = original }(programs)
+	programs = []azstocker.Program{azstocker.WinterProgram}
+
+	// responses were fetched 10 minutes ago, so they are still fresh in the HTTP cache
+	fetchedAt := time.Now().Add(-10 * time.Minute)
+	numRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"):
+			resp = sheets.ValueRange{Values: [][]any{{"OCTOBER"}, {"1"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{{"Phoenix Area"}, {"  LOWER SALT RIVER", "X"}}}
+		default:
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}}}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Date", fetchedAt.UTC().Format(http.TimeFormat))
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: transport.NewCacheControl(time.Hour, ts.Client().Transport)}
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(client))
+	assert.NoError(t, err)
+
+	s, err := newServer(srv, "http://example.com", WithReadyMaxAge(time.Minute))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, numRequests)
+
+	// the data is older than the max age, but the cached responses are used instead of fetching again
+	s.status[azstocker.WinterProgram].LastFetch = &fetchedAt
+
+	w = httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), errStaleData.Error())
+	assert.Equal(t, 3, numRequests)
+}
+
+func TestRouteSpanName(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+	s, err := newServer(nil, "http://example.com")
+	assert.NoError(t, err)
+	handler := otelhttp.NewHandler(s.handler(), "azstocker", otelhttp.WithTracerProvider(provider))
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/healthz", "GET /healthz"},
+		{"/manifest.json", "GET /manifest.json"},
+		{"/robots.txt?lang=es", "GET /robots.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			ended := spans.Ended()
+			if assert.NotEmpty(t, ended) {
+				assert.Equal(t, tt.expected, ended[len(ended)-1].Name())
+			}
+		})
+	}
+}
+
+func TestMetricsAuth(t *testing.T) {
+	s, err := newServer(nil, "http://example.com", WithMetrics(MetricsConfig{
+		Mount:       true,
+		Path:        "internal/metrics",
+		Username:    "user",
+		Password:    "pass",
+		BearerToken: "token",
+	}))
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	tests := []struct {
+		name     string
+		setAuth  func(*http.Request)
+		expected int
+	}{
+		{"NoAuth", func(*http.Request) {}, http.StatusUnauthorized},
+		{"BasicAuth", func(r *http.Request) { r.SetBasicAuth("user", "pass") }, http.StatusOK},
+		{"WrongPassword", func(r *http.Request) { r.SetBasicAuth("user", "wrong") }, http.StatusUnauthorized},
+		{"BearerToken", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }, http.StatusOK},
+		{"WrongToken", func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/internal/metrics", http.NoBody)
+			tt.setAuth(r)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	s, err := newServer(nil, "http://example.com")
+	assert.NoError(t, err)
+
+	getManifest := func(cookie *http.Cookie) webManifest {
+		r := httptest.NewRequest(http.MethodGet, "/manifest.json", http.NoBody)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, r)
+		assert.Equal(t, "application/manifest+json", w.Header().Get("Content-Type"))
+
+		var manifest webManifest
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifest))
+		return manifest
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		manifest := getManifest(nil)
+		assert.Equal(t, "AZStocker", manifest.ShortName)
+		assert.Len(t, manifest.Icons, 4)
+		assert.Len(t, manifest.Shortcuts, 3)
+	})
+
+	t.Run("Favorites", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setFavoritesCookie(w, azstocker.WinterProgram, []string{"LYNX LAKE", "WATSON LAKE"})
+
+		manifest := getManifest(w.Result().Cookies()[0])
+		assert.Len(t, manifest.Shortcuts, 4)
+		assert.Equal(t, "/winter?waters=LYNX+LAKE%2CWATSON+LAKE", manifest.Shortcuts[3].URL)
+	})
+
+	t.Run("InvalidFavorites", func(t *testing.T) {
+		manifest := getManifest(&http.Cookie{Name: favoritesCookie, Value: url.QueryEscape("https://evil.com/x")})
+		assert.Len(t, manifest.Shortcuts, 3)
+	})
+}
+
+func TestLists(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"), strings.HasSuffix(r.URL.Path, "!B8:9"):
+			resp = sheets.ValueRange{Values: [][]any{{"OCTOBER"}, {"1"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{{"Prescott Area"}, {"  LYNX LAKE", "X"}, {"  WATSON LAKE", "X"}}}
+		case strings.HasSuffix(r.URL.Path, "!A11:Z"):
+			resp = sheets.ValueRange{Values: [][]any{{"Tempe - Kiwanis Lake", "X"}}}
+		default:
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+				{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}},
+				{Properties: &sheets.SheetProperties{Title: "CFP Stocking Calendar Schedule"}},
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+
+	s, err := newServer(srv, "http://example.com", WithListsDir(t.TempDir()))
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	createList := func(form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/l", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := createList(url.Values{
+		"name":    []string{"Home Lakes"},
+		"program": []string{"winter"},
+		"waters":  []string{"LYNX LAKE, watson lake"},
+	})
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	id := strings.TrimPrefix(w.Header().Get("Location"), "/l/")
+	list, err := s.getList(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Home Lakes", list.Name)
+	assert.Equal(t, map[azstocker.Program][]string{
+		azstocker.WinterProgram: {"LYNX LAKE", "WATSON LAKE"},
+	}, list.Waters)
+
+	t.Run("Extend", func(t *testing.T) {
+		w := createList(url.Values{
+			"extend":  []string{id},
+			"program": []string{"cfp"},
+			"waters":  []string{"Tempe - Kiwanis Lake"},
+		}, w.Result().Cookies()...)
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+
+		newID := strings.TrimPrefix(w.Header().Get("Location"), "/l/")
+		assert.NotEqual(t, id, newID)
+
+		list, err := s.getList(newID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Home Lakes", list.Name)
+		assert.Len(t, list.Waters, 2)
+
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		r.AddCookie(w.Result().Cookies()[0])
+		assert.Equal(t, []string{newID}, savedListIDs(r))
+	})
+
+	t.Run("MissingName", func(t *testing.T) {
+		w := createList(url.Values{"program": []string{"winter"}, "waters": []string{"LYNX LAKE"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownWater", func(t *testing.T) {
+		w := createList(url.Values{
+			"name":    []string{"Home Lakes"},
+			"program": []string{"winter"},
+			"waters":  []string{"LYNX LAKE," + strings.Repeat("x", 1000)},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "unknown water\n", w.Body.String())
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		w := createList(url.Values{
+			"name":    []string{"Home Lakes"},
+			"program": []string{"winter"},
+			"waters":  []string{strings.Repeat("LYNX LAKE,", maxListFormBytes)},
+		})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		for _, path := range []string{"/l/abcdefgh", "/l/..%2F..%2Fetc"} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestListStoreLimit(t *testing.T) {
+	stores := map[string]listStore{
+		"Memory": &memoryListStore{lists: map[string]favoriteList{}, limit: 2},
+		"File":   &fileListStore{dir: t.TempDir(), limit: 2},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			modTime := time.Now().Add(-time.Hour)
+			for _, id := range []string{"aaaaaaaa", "bbbbbbbb", "cccccccc"} {
+				assert.NoError(t, store.save(favoriteList{ID: id, Name: id}))
+
+				// file modification times might not be precise enough to order lists saved quickly
+				if fileStore, ok := store.(*fileListStore); ok {
+					assert.NoError(t, os.Chtimes(fileStore.filename(id), modTime, modTime))
+					modTime = modTime.Add(time.Minute)
+				}
+			}
+
+			_, err := store.get("aaaaaaaa")
+			assert.ErrorIs(t, err, errListNotFound)
+			for _, id := range []string{"bbbbbbbb", "cccccccc"} {
+				list, err := store.get(id)
+				assert.NoError(t, err)
+				assert.Equal(t, id, list.Name)
+			}
+		})
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		cookie   string
+		expected i18n.Language
+	}{
+		{"Default", "/", "", "", i18n.English},
+		{"AcceptLanguage", "/", "es-MX,es;q=0.9", "", i18n.Spanish},
+		{"QueryParam", "/?lang=es", "en-US", "", i18n.Spanish},
+		{"Cookie", "/", "en-US", "es", i18n.Spanish},
+		{"QueryOverridesCookie", "/?lang=en", "", "es", i18n.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			r.Header.Set("Accept-Language", tt.header)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: langCookie, Value: tt.cookie})
+			}
+
+			assert.Equal(t, tt.expected, language(httptest.NewRecorder(), r))
+		})
+	}
+
+	t.Run("QueryOnly", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/winter/og.png", http.NoBody)
+		r.Header.Set("Accept-Language", "es-MX")
+		r.AddCookie(&http.Cookie{Name: langCookie, Value: "es"})
+		assert.Equal(t, i18n.English, queryLanguage(r))
+
+		r = httptest.NewRequest(http.MethodGet, "/winter/og.png?lang=es", http.NoBody)
+		assert.Equal(t, i18n.Spanish, queryLanguage(r))
+	})
+
+	t.Run("Homepage", func(t *testing.T) {
+		s, err := newServer(nil, "http://example.com")
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?lang=es", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `<html lang="es">`)
+		assert.Contains(t, w.Body.String(), "Calendarios de Siembra de Arizona GFD")
+	})
+}
+
+func TestRobots(t *testing.T) {
+	s, err := newServer(nil, "http://example.com", WithMetrics(MetricsConfig{Mount: true}))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/robots.txt", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Disallow: /l/\n")
+	assert.Contains(t, w.Body.String(), "Disallow: /export/\n")
+	assert.Contains(t, w.Body.String(), "Disallow: /metrics\n")
+	assert.Contains(t, w.Body.String(), "Sitemap: http://example.com/sitemap.xml\n")
+}
+
+func TestScheduleDir(t *testing.T) {
+	dir := t.TempDir()
+	schedule := strings.Join([]string{
+		"", "", "",
+		",October,,November",
+		",7,14,4",
+		"", "", "",
+		"Lynx Lake,X,,C",
+	}, "\n")
+	err := os.WriteFile(filepath.Join(dir, "Winter Stocking - 2024-25 Winter.csv"), []byte(schedule), 0o600)
+	assert.NoError(t, err)
+
+	s, err := newServer(nil, "http://example.com", WithScheduleDir(dir))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/winter?waters=Lynx+Lake&showAll=true", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Lynx Lake")
+
+	t.Run("MissingDir", func(t *testing.T) {
+		_, err := newServer(nil, "http://example.com", WithScheduleDir(filepath.Join(dir, "missing")))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestRunServerShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var startOnce, releaseOnce sync.Once
+	releaseSheets := func() { releaseOnce.Do(func() { close(release) }) }
+	// the fake Sheets API can't stop until the request is released
+	defer releaseSheets()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"):
+			resp = sheets.ValueRange{Values: [][]any{{"OCTOBER"}, {"7"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{{"Prescott Area"}, {"  LYNX LAKE", "X"}}}
+		default:
+			// the page request is in-flight until the test releases it
+			startOnce.Do(func() { close(started) })
+			<-release
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+				{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}},
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+	assert.NoError(t, l.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- RunServer(ctx, addr, srv, "http://example.com", WithMetrics(MetricsConfig{Mount: true}))
+	}()
+
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	type response struct {
+		code int
+		err  error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/winter")
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		responses <- response{resp.StatusCode, err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "request did not start")
+		return
+	}
+	cancel()
+
+	// the server keeps running while the request is in-flight
+	select {
+	case err := <-runErr:
+		assert.Fail(t, "server stopped with a request in-flight", "err: %v", err)
+		return
+	case <-time.After(100 * time.Millisecond):
+	}
+	releaseSheets()
+
+	resp := <-responses
+	assert.NoError(t, resp.err)
+	assert.Equal(t, http.StatusOK, resp.code)
+	assert.NoError(t, <-runErr)
+
+	// new requests are refused after shutting down
+	_, err = http.Get("http://" + addr + "/healthz")
+	assert.Error(t, err)
 }
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+	// sitemapCacheTTL is how long sitemap URLs are kept before getting fresh data. Crawlers request
+	// sitemaps often and they do not need to be up to date
+	sitemapCacheTTL = time.Hour
+)
+
+type sitemapIndexXML struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapXML `xml:"sitemap"`
+}
+
+type sitemapXML struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSetXML struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []urlXML `xml:"url"`
+}
+
+type urlXML struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// sitemapCache keeps the URLs for each program's sitemap so crawlers do not cause a fetch for each request
+type sitemapCache struct {
+	mu       sync.Mutex
+	programs map[azstocker.Program]cachedSitemap
+}
+
+type cachedSitemap struct {
+	urls    []sitemapURL
+	expires time.Time
+}
+
+func (c *sitemapCache) get(program azstocker.Program) (cachedSitemap, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.programs[program]
+	return cached, ok
+}
+
+func (c *sitemapCache) set(program azstocker.Program, urls []sitemapURL) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.programs == nil {
+		c.programs = map[azstocker.Program]cachedSitemap{}
+	}
+	c.programs[program] = cachedSitemap{urls, time.Now().Add(sitemapCacheTTL)}
+}
+
+// sitemapURLs gets the program page and a page for each water. The first URL is the program page and its
+// lastmod is the most recent change to any water. If data can't be fetched, expired URLs are used instead
+// of leaving the program out of the sitemap
+func (s *server) sitemapURLs(ctx context.Context, program azstocker.Program) ([]sitemapURL, error) {
+	cached, ok := s.sitemaps.get(program)
+	if ok && time.Now().Before(cached.expires) {
+		return cached.urls, nil
+	}
+
+	stockingData, err := s.getStockingData(ctx, program, []string{})
+	if err != nil {
+		if ok {
+			slog.Log(ctx, slog.LevelWarn, "using expired sitemap", "program", program, "err", err.Error())
+			return cached.urls, nil
+		}
+		return nil, fmt.Errorf("error getting data for %s: %w", program, err)
+	}
+
+	stockingData.Sort(func(c1, c2 azstocker.Calendar) int {
+		return strings.Compare(c1.WaterName, c2.WaterName)
+	})
+
+	programURL := sitemapURL{Loc: fmt.Sprintf("%s/%s", s.urlBase, program)}
+	urls := []sitemapURL{programURL}
+	for _, data := range stockingData {
+		loc := s.waterURL(program, data.WaterName)
+		if slices.ContainsFunc(urls, func(u sitemapURL) bool { return u.Loc == loc }) {
+			continue
+		}
+
+		lastMod := s.waterLastModified(program, data.WaterName)
+		if lastMod.After(urls[0].LastMod) {
+			urls[0].LastMod = lastMod
+		}
+		urls = append(urls, sitemapURL{Loc: loc, LastMod: lastMod})
+	}
+
+	s.sitemaps.set(program, urls)
+	return urls, nil
+}
+
+// waterLastModified is when the water's schedule last changed from the changeTracker. If the changes are not
+// saved, this is when the data was first fetched after the server started. It is zero if it is not known, so
+// lastmod is left out
+func (s *server) waterLastModified(program azstocker.Program, water string) time.Time {
+	lastMod, ok := s.changes.lastModified(program, water)
+	if !ok {
+		return time.Time{}
+	}
+	return lastMod
+}
+
+// sitemapIndex links to the sitemap for each program
+func (s *server) sitemapIndex(w http.ResponseWriter, r *http.Request) {
+	index := sitemapIndexXML{XMLNS: sitemapNamespace}
+	for _, p := range programs {
+		sitemap := sitemapXML{Loc: fmt.Sprintf("%s/sitemap-%s.xml", s.urlBase, p)}
+
+		urls, err := s.sitemapURLs(r.Context(), p)
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to get sitemap", "err", err.Error())
+		} else {
+			sitemap.LastMod = formatLastMod(urls[0].LastMod)
+		}
+
+		index.Sitemaps = append(index.Sitemaps, sitemap)
+	}
+
+	writeXML(w, r, index)
+}
+
+// programSitemap lists the program page and the page for each water
+func (s *server) programSitemap(program azstocker.Program) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		urls, err := s.sitemapURLs(r.Context(), program)
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to get sitemap", "err", err.Error())
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		urlSet := urlSetXML{XMLNS: sitemapNamespace}
+		for _, u := range urls {
+			urlSet.URLs = append(urlSet.URLs, urlXML{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)})
+		}
+
+		writeXML(w, r, urlSet)
+	}
+}
+
+func (s *server) sitemap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := s.writeSitemap(r.Context(), w)
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to get sitemap", "err", err.Error())
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// writeSitemap writes the plain text sitemap. Nothing is written unless all programs are available
+func (s *server) writeSitemap(ctx context.Context, w io.Writer) error {
+	var sb strings.Builder
+	for _, p := range programs {
+		urls, err := s.sitemapURLs(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range urls {
+			fmt.Fprintf(&sb, "%s\n", u.Loc)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// robots allows crawling the schedules and links to the sitemap. Personal lists and internal endpoints are
+// excluded
+func (s *server) robots(w http.ResponseWriter, r *http.Request) {
+	disallow := []string{"/l/", "/notify", "/healthz", "/readyz", "/status", "/export/"}
+	if s.metrics.Mount {
+		disallow = append(disallow, s.metrics.path())
+	}
+
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\n")
+	for _, path := range disallow {
+		fmt.Fprintf(&sb, "Disallow: %s\n", path)
+	}
+	fmt.Fprintf(&sb, "\nSitemap: %s/sitemap.xml\n", s.urlBase)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func writeXML(w http.ResponseWriter, r *http.Request, data any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(sitemapCacheTTL.Seconds())))
+	_, _ = w.Write([]byte(xml.Header))
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err := enc.Encode(data)
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to write XML", "err", err.Error())
+	}
+}
+
+// formatLastMod uses the W3C datetime format required by the sitemap protocol
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
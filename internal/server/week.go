@@ -0,0 +1,76 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/azstocker"
+)
+
+// thisWeek shows every water stocked in the current week across all programs. The date query parameter
+// shows a different week using the Season that has it, like azstocker.GetWeekDigest. A program that fails
+// to load is left out so the others are still shown
+func (s *server) thisWeek(w http.ResponseWriter, r *http.Request) {
+	date, err := query{r}.Date("date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if date.IsZero() {
+		date = azstocker.Now()
+	}
+
+	start, end := azstocker.WeekOf(date)
+
+	data := map[azstocker.Program]azstocker.StockingData{}
+	unavailable := []azstocker.Program{}
+	for _, program := range programs {
+		seasons, err := azstocker.Seasons(r.Context(), s.srv, program, s.sourceOptions()...)
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to get seasons for digest", "program", program, "err", err.Error())
+			unavailable = append(unavailable, program)
+			continue
+		}
+
+		// without any Seasons, the default Season is used so the error is the same as other pages
+		seasonOpts := []azstocker.Option{}
+		season, ok := azstocker.CurrentSeason(seasons, start)
+		if ok {
+			if !(season.Contains(start) || season.Contains(end)) {
+				continue
+			}
+			seasonOpts = append(seasonOpts, azstocker.WithSeason(season))
+		}
+
+		stockingData, err := s.getStockingData(r.Context(), program, nil, seasonOpts...)
+		if err != nil {
+			slog.Log(r.Context(), slog.LevelError, "failed to get stocking data for digest", "program", program, "err", err.Error())
+			unavailable = append(unavailable, program)
+			continue
+		}
+		data[program] = stockingData
+	}
+	digest := azstocker.NewWeekDigest(date, data)
+
+	tmpl, err := loadTemplates()
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to parse template", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = executeTemplate(r.Context(), tmpl, w, "thisWeek", withLanguage(r, language(w, r), map[string]any{
+		"program":       "thisWeek",
+		"digest":        digest,
+		"unavailable":   unavailable,
+		"previousWeek":  digest.Start.AddDate(0, 0, -7).Format(time.DateOnly),
+		"nextWeek":      digest.Start.AddDate(0, 0, 7).Format(time.DateOnly),
+		"notifyEnabled": s.notifyEnabled(r),
+	}))
+	if err != nil {
+		slog.Log(r.Context(), slog.LevelError, "failed to execute template", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestThisWeek(t *testing.T) {
+	// only the Winter spreadsheet has a matching season, so the other programs fail to load
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch {
+		case strings.HasSuffix(r.URL.Path, "!B4:5"):
+			resp = sheets.ValueRange{Values: [][]any{{"OCTOBER"}, {"1"}}}
+		case strings.Contains(r.URL.Path, "2023-24 Winter") && strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{{"Prescott Area"}, {"  GOLDWATER LAKE", "X"}}}
+		case strings.HasSuffix(r.URL.Path, "!A9:AD"):
+			resp = sheets.ValueRange{Values: [][]any{{"Phoenix Area"}, {"  LOWER SALT RIVER", "X"}}}
+		default:
+			resp = sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+				{Properties: &sheets.SheetProperties{Title: "2023-24 Winter"}},
+				{Properties: &sheets.SheetProperties{Title: "2024-25 Winter"}},
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	srv, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithHTTPClient(ts.Client()))
+	assert.NoError(t, err)
+
+	s, err := newServer(srv, "http://example.com")
+	assert.NoError(t, err)
+	handler := s.handler()
+
+	t.Run("Stocked", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/this-week?date=2024-10-02", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "September 30 – October 6, 2024")
+		assert.Contains(t, body, "Phoenix Area")
+		assert.Contains(t, body, "LOWER SALT RIVER")
+		assert.Contains(t, body, `href="/this-week?date=2024-09-23"`)
+		assert.Contains(t, body, `href="/this-week?date=2024-10-07"`)
+		assert.Contains(t, body, "The Community Fishing Program schedule is unavailable right now")
+	})
+
+	t.Run("PreviousSeason", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/this-week?date=2023-09-29", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "September 25 – October 1, 2023")
+		assert.Contains(t, body, "GOLDWATER LAKE")
+		assert.NotContains(t, body, "LOWER SALT RIVER")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/this-week?date=2024-10-16&lang=es", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "No hay siembras programadas para esta semana.")
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/this-week?date=tomorrow", http.NoBody))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
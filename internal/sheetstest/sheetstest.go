@@ -0,0 +1,52 @@
+// Package sheetstest has helpers for testing with the recorded Google Sheets API fixtures
+package sheetstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// FixtureSheets are the sheet titles in each spreadsheet. The fixtures were recorded before seasons were
+// found from the spreadsheet metadata, so the metadata is not in them. Only the last Winter season has
+// recorded values
+var FixtureSheets = map[string][]string{
+	"1xJYPRrX2Gb7ACr6HxPB7mlsCw9K8NvClLfBIw7qjTcA": {"CFP Stocking Calendar Schedule"},
+	"1PZuTV-zi5vMdxaMSnGx6c-QxeQQm-6DRQJJPKAZDjZM": {"2023-24 Winter", "2024-25 Winter"},
+}
+
+const spreadsheetsPrefix = "/v4/spreadsheets/"
+
+// WithMetadata responds to spreadsheet metadata requests with the FixtureSheets, like an httptest server.
+// Other requests, like getting values, are sent to next
+func WithMetadata(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		id, ok := strings.CutPrefix(r.URL.Path, spreadsheetsPrefix)
+		if !ok || strings.Contains(id, "/") {
+			return next.RoundTrip(r)
+		}
+
+		resp := sheets.Spreadsheet{SpreadsheetId: id}
+		for _, title := range FixtureSheets[id] {
+			resp.Sheets = append(resp.Sheets, &sheets.Sheet{Properties: &sheets.SheetProperties{Title: title}})
+		}
+
+		w := httptest.NewRecorder()
+		if len(resp.Sheets) == 0 {
+			http.Error(w, "Requested entity was not found.", http.StatusNotFound)
+			return w.Result(), nil
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+		return w.Result(), nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
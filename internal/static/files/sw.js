@@ -0,0 +1,87 @@
+// Service worker for AZStocker. Static assets are cached when it is installed and schedule pages are cached
+// as they are viewed so the last schedules are available offline.
+const STATIC_CACHE = "azstocker-static-{{ .Version }}";
+const PAGES_CACHE = "azstocker-pages";
+const MAX_PAGES = 25;
+const PRECACHE = {{ .Precache }};
+
+self.addEventListener("install", (event) => {
+    event.waitUntil(
+        caches.open(STATIC_CACHE)
+            .then((cache) => cache.addAll(PRECACHE))
+            .then(() => self.skipWaiting())
+    );
+});
+
+self.addEventListener("activate", (event) => {
+    event.waitUntil(
+        caches.keys()
+            .then((keys) => Promise.all(
+                keys
+                    .filter((key) => key.startsWith("azstocker-static-") && key !== STATIC_CACHE)
+                    .map((key) => caches.delete(key))
+            ))
+            .then(() => self.clients.claim())
+    );
+});
+
+self.addEventListener("fetch", (event) => {
+    const request = event.request;
+    const url = new URL(request.url);
+    if (request.method !== "GET" || url.origin !== self.location.origin) {
+        return;
+    }
+
+    if (url.pathname.startsWith("/static/")) {
+        event.respondWith(cacheFirst(request));
+        return;
+    }
+
+    if (request.mode === "navigate") {
+        event.respondWith(networkFirst(request));
+    }
+});
+
+async function cacheFirst(request) {
+    const cached = await caches.match(request);
+    if (cached) {
+        return cached;
+    }
+
+    const response = await fetch(request);
+    if (response.ok) {
+        const cache = await caches.open(STATIC_CACHE);
+        cache.put(request, response.clone());
+    }
+    return response;
+}
+
+async function networkFirst(request) {
+    const cache = await caches.open(PAGES_CACHE);
+    try {
+        const response = await fetch(request);
+        if (response.ok) {
+            await cache.put(request, response.clone());
+            await trimCache(cache);
+        }
+        return response;
+    } catch (err) {
+        const cached = await cache.match(request);
+        if (cached) {
+            return cached;
+        }
+        const home = await caches.match("/");
+        if (home) {
+            return home;
+        }
+        throw err;
+    }
+}
+
+// trimCache removes the oldest pages so only the most recently viewed are kept
+async function trimCache(cache) {
+    const keys = await cache.keys();
+    for (const key of keys.slice(0, Math.max(keys.length - MAX_PAGES, 0))) {
+        await cache.delete(key);
+    }
+}
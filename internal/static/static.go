@@ -0,0 +1,148 @@
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+//go:generate go run ../tools/vendorassets
+//go:generate go run ../tools/icons
+
+const (
+	// Prefix is the URL path that static files are served under
+	Prefix = "/static/"
+
+	// VendorDir is the directory in files that third-party assets are downloaded to
+	VendorDir = "vendor"
+
+	immutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+// Asset is a third-party frontend asset that is vendored so the site works without a CDN
+type Asset struct {
+	Name   string
+	Source string
+}
+
+// Vendor is the list of pinned third-party assets. They are downloaded to files/vendor with go generate
+// when building, so the binary never loads them from a CDN
+var Vendor = []Asset{
+	{"uikit.min.css", "https://cdn.jsdelivr.net/npm/uikit@3.21.13/dist/css/uikit.min.css"},
+	{"uikit.min.js", "https://cdn.jsdelivr.net/npm/uikit@3.21.13/dist/js/uikit.min.js"},
+	{"uikit-icons.min.js", "https://cdn.jsdelivr.net/npm/uikit@3.21.13/dist/js/uikit-icons.min.js"},
+	{"hyperscript.min.js", "https://unpkg.com/hyperscript.org@0.9.13"},
+}
+
+//go:embed files
+var files embed.FS
+
+var filesFS = func() fs.FS {
+	sub, err := fs.Sub(files, "files")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
+// hashes maps file names to a short content hash used for cache-busting
+var hashes = sync.OnceValue(func() map[string]string {
+	result := map[string]string{}
+	_ = fs.WalkDir(filesFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(filesFS, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		result[name] = hex.EncodeToString(sum[:])[:12]
+		return nil
+	})
+	return result
+})
+
+// URL returns the cache-busting URL for a static file. Vendored assets are found by name in VendorDir. A
+// missing file gets a URL without a hash, and the tests check that every vendored asset is embedded after
+// running go generate
+func URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if isVendored(name) {
+		name = path.Join(VendorDir, name)
+	}
+
+	hash, ok := hashes()[name]
+	if !ok {
+		return Prefix + name
+	}
+	return Prefix + name + "?v=" + hash
+}
+
+func isVendored(name string) bool {
+	for _, asset := range Vendor {
+		if asset.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler serves the embedded files. Requests with the current hash are cached forever since a change
+// to the file will change the URL
+func Handler() http.Handler {
+	fileServer := http.StripPrefix(Prefix, http.FileServerFS(filesFS))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, Prefix)
+		hash, ok := hashes()[name]
+		if ok && r.URL.Query().Get("v") == hash {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// ServiceWorker serves the service worker script. It has to be served from the root path so it can
+// control all pages
+func ServiceWorker() http.Handler {
+	tmpl := template.Must(template.ParseFS(filesFS, "sw.js"))
+
+	urls := []string{"/"}
+	var version strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(hashes())) {
+		if name == "sw.js" {
+			continue
+		}
+		urls = append(urls, Prefix+name+"?v="+hashes()[name])
+		version.WriteString(hashes()[name])
+	}
+	versionSum := sha256.Sum256([]byte(version.String()))
+	precache, _ := json.Marshal(urls)
+
+	var body bytes.Buffer
+	err := tmpl.Execute(&body, map[string]string{
+		"Version":  hex.EncodeToString(versionSum[:])[:12],
+		"Precache": string(precache),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write(body.Bytes())
+	})
+}
@@ -0,0 +1,76 @@
+package static
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURL(t *testing.T) {
+	t.Run("EmbeddedFile", func(t *testing.T) {
+		assert.Equal(t, "/static/sw.js?v="+hashes()["sw.js"], URL("sw.js"))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		assert.Equal(t, "/static/missing.js", URL("missing.js"))
+	})
+}
+
+// TestVendorAssets makes sure the pinned assets are embedded and precached by the service worker. They
+// are downloaded by go generate ./internal/static, which is run by CI and the Dockerfile
+func TestVendorAssets(t *testing.T) {
+	_, err := fs.Stat(filesFS, VendorDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("vendored assets are not downloaded. Run go generate ./internal/static")
+	}
+
+	w := httptest.NewRecorder()
+	ServiceWorker().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sw.js", http.NoBody))
+
+	for _, asset := range Vendor {
+		t.Run(asset.Name, func(t *testing.T) {
+			name := VendorDir + "/" + asset.Name
+			hash, ok := hashes()[name]
+			if !assert.True(t, ok, "%s is not embedded", name) {
+				return
+			}
+			assert.Equal(t, Prefix+name+"?v="+hash, URL(asset.Name))
+			assert.Contains(t, w.Body.String(), `"`+URL(asset.Name)+`"`)
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		cacheControl string
+	}{
+		{"CurrentHash", URL("sw.js"), immutableCacheControl},
+		{"OldHash", "/static/sw.js?v=old", "no-cache"},
+		{"NoHash", "/static/sw.js", "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, http.NoBody))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.cacheControl, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestServiceWorker(t *testing.T) {
+	w := httptest.NewRecorder()
+	ServiceWorker().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sw.js", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript"))
+	assert.Contains(t, w.Body.String(), `const PRECACHE = ["/"`)
+	assert.NotContains(t, w.Body.String(), "{{")
+}
@@ -0,0 +1,116 @@
+// icons renders the app icons used by the PWA manifest. It is run with go generate from the internal/static
+// package and the output is committed
+package main
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+var (
+	background = color.RGBA{0x1e, 0x87, 0xf0, 0xff}
+	foreground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+type icon struct {
+	name string
+	size int
+	// scale shrinks the fish toward the center. Maskable icons need content inside the center 80%
+	scale float64
+}
+
+var icons = []icon{
+	{"icon-192.png", 192, 1},
+	{"icon-512.png", 512, 1},
+	{"icon-maskable-512.png", 512, 0.75},
+	{"apple-touch-icon.png", 180, 0.9},
+	{"favicon-32.png", 32, 1.1},
+}
+
+func main() {
+	out := flag.String("out", filepath.Join("files", "icons"), "directory to write icons to")
+	flag.Parse()
+
+	err := os.MkdirAll(*out, 0o755)
+	if err != nil {
+		log.Fatalf("error creating output directory: %v", err)
+	}
+
+	for _, i := range icons {
+		err := writePNG(filepath.Join(*out, i.name), render(i.size, i.scale))
+		if err != nil {
+			log.Fatalf("error writing %s: %v", i.name, err)
+		}
+	}
+}
+
+func writePNG(filename string, img image.Image) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
+
+// render draws a fish on a solid background. Each pixel is supersampled to smooth the edges
+func render(size int, scale float64) image.Image {
+	const samples = 4
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for py := range size {
+		for px := range size {
+			covered := 0
+			for sy := range samples {
+				for sx := range samples {
+					// normalize to [-0.5, 0.5] around the center and apply scale
+					x := ((float64(px)+(float64(sx)+0.5)/samples)/float64(size) - 0.5) / scale
+					y := ((float64(py)+(float64(sy)+0.5)/samples)/float64(size) - 0.5) / scale
+					if inFish(x, y) {
+						covered++
+					}
+				}
+			}
+			img.Set(px, py, blend(background, foreground, float64(covered)/(samples*samples)))
+		}
+	}
+	return img
+}
+
+// inFish uses the same shapes as icon.svg: an elliptical body, a triangle tail and an eye
+func inFish(x, y float64) bool {
+	// eye
+	if sq(x+0.2)+sq(y+0.04) < sq(0.03) {
+		return false
+	}
+
+	// body
+	if sq((x+0.05)/0.28)+sq(y/0.16) <= 1 {
+		return true
+	}
+
+	// tail is a triangle pointing at the body
+	if x >= 0.18 && x <= 0.36 {
+		halfHeight := (x - 0.18) / 0.18 * 0.17
+		return y >= -halfHeight && y <= halfHeight
+	}
+
+	return false
+}
+
+func sq(v float64) float64 {
+	return v * v
+}
+
+func blend(bg, fg color.RGBA, alpha float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a)*(1-alpha) + float64(b)*alpha + 0.5)
+	}
+	return color.RGBA{mix(bg.R, fg.R), mix(bg.G, fg.G), mix(bg.B, fg.B), 0xff}
+}
@@ -0,0 +1,56 @@
+// vendorassets downloads the pinned third-party frontend assets so they can be embedded in the binary.
+// It is run with go generate from the internal/static package
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/calvinmclean/azstocker/internal/static"
+)
+
+func main() {
+	out := flag.String("out", filepath.Join("files", static.VendorDir), "directory to download assets to")
+	flag.Parse()
+
+	err := os.MkdirAll(*out, 0o755)
+	if err != nil {
+		log.Fatalf("error creating output directory: %v", err)
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	for _, asset := range static.Vendor {
+		err := download(client, asset.Source, filepath.Join(*out, asset.Name))
+		if err != nil {
+			log.Fatalf("error downloading %s: %v", asset.Name, err)
+		}
+		log.Printf("downloaded %s", asset.Name)
+	}
+}
+
+func download(client *http.Client, url, dest string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
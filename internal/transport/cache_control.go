@@ -8,12 +8,16 @@ import (
 	"github.com/gregjones/httpcache"
 	"github.com/gregjones/httpcache/diskcache"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-var httpCacheMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+var tracer = otel.Tracer("github.com/calvinmclean/azstocker/internal/transport")
+
+var httpCacheMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Namespace: "azstocker",
-	Name:      "http_client_cache",
-	Help:      "gauge of cache usage",
+	Name:      "http_client_cache_total",
+	Help:      "count of requests by cache usage",
 }, []string{"path", "cache_used"})
 
 func init() {
@@ -45,10 +49,20 @@ func newCacheControl(cache httpcache.Cache, maxAge time.Duration, next http.Roun
 }
 
 func (h *cacheControl) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := tracer.Start(r.Context(), "cache")
+	defer span.End()
+	r = r.WithContext(ctx)
+
 	r.Header.Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(h.maxAge.Seconds())))
 	resp, err := h.rt.RoundTrip(r)
 	if resp != nil {
-		httpCacheMetric.WithLabelValues(r.URL.Path, fmt.Sprint(cacheUsed(resp.Header))).Inc()
+		used := cacheUsed(resp.Header)
+		span.SetAttributes(attribute.Bool("cache.hit", used))
+		httpCacheMetric.WithLabelValues(r.URL.Path, fmt.Sprint(used)).Inc()
+		trackResponse(ctx, resp, used)
+	}
+	if err != nil {
+		span.RecordError(err)
 	}
 	return resp, err
 }
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: NewCacheControl(time.Minute, http.DefaultTransport)}
+
+	get := func() (bool, time.Time) {
+		ctx := TrackUpstream(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, http.NoBody)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		// the response is only cached after reading the body
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		fetchedAt, ok := FetchedAt(ctx)
+		assert.True(t, ok)
+		return FromUpstream(ctx), fetchedAt
+	}
+
+	fromUpstream, fetchedAt := get()
+	assert.True(t, fromUpstream)
+	assert.WithinDuration(t, time.Now(), fetchedAt, 5*time.Second)
+
+	fromUpstream, cachedFetchedAt := get()
+	assert.False(t, fromUpstream, "second response should be from the cache")
+	// the Date header only has seconds
+	assert.WithinDuration(t, fetchedAt, cachedFetchedAt, time.Second, "cached response should keep the original fetch time")
+
+	assert.False(t, FromUpstream(context.Background()))
+	_, ok := FetchedAt(context.Background())
+	assert.False(t, ok)
+}
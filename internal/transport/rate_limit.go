@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// ErrQuotaExhausted is returned when a request cannot get a token from the rate limiter before
+// the configured max wait time
+var ErrQuotaExhausted = errors.New("upstream request quota exhausted")
+
+var (
+	upstreamRequestsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "azstocker",
+		Name:      "upstream_requests_total",
+		Help:      "count of requests sent to the upstream Sheets API",
+	}, []string{"spreadsheet_id"})
+
+	quotaExhaustedMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "azstocker",
+		Name:      "quota_exhausted_total",
+		Help:      "count of requests rejected by the local rate limiter or the upstream API",
+	}, []string{"spreadsheet_id", "source"})
+)
+
+func init() {
+	prometheus.MustRegister(upstreamRequestsMetric, quotaExhaustedMetric)
+}
+
+// RateLimitConfig controls the token-bucket used to limit upstream requests
+type RateLimitConfig struct {
+	// PerMinute is the number of requests allowed per minute. If it is zero, requests are not limited
+	PerMinute float64
+	// Burst is the maximum number of requests that can be made at once
+	Burst int
+	// MaxWait is how long a request will wait for a token before it is rejected. If it is zero,
+	// excess requests are rejected immediately
+	MaxWait time.Duration
+}
+
+// rateLimit caps the number of requests made by the next RoundTripper and records upstream usage.
+// It is intended to sit below the cache so only requests that reach the API are counted
+type rateLimit struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	maxWait time.Duration
+}
+
+func NewRateLimit(cfg RateLimitConfig, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	limit := rate.Inf
+	if cfg.PerMinute > 0 {
+		limit = rate.Limit(cfg.PerMinute / 60)
+	}
+	burst := cfg.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimit{next, rate.NewLimiter(limit, burst), cfg.MaxWait}
+}
+
+func (rl *rateLimit) RoundTrip(r *http.Request) (*http.Response, error) {
+	spreadsheetID := spreadsheetIDFromPath(r.URL.Path)
+
+	err := rl.wait(r.Context())
+	if err != nil {
+		quotaExhaustedMetric.WithLabelValues(spreadsheetID, "local").Inc()
+		return nil, fmt.Errorf("%w: %w", ErrQuotaExhausted, err)
+	}
+
+	upstreamRequestsMetric.WithLabelValues(spreadsheetID).Inc()
+	resp, err := rl.next.RoundTrip(r)
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		quotaExhaustedMetric.WithLabelValues(spreadsheetID, "upstream").Inc()
+	}
+	return resp, err
+}
+
+// wait blocks until a token is available. When maxWait is set, the request is rejected if it
+// would need to wait longer than that
+func (rl *rateLimit) wait(ctx context.Context) error {
+	if rl.maxWait <= 0 {
+		if !rl.limiter.Allow() {
+			return errors.New("no tokens available")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rl.maxWait)
+	defer cancel()
+
+	return rl.limiter.Wait(ctx)
+}
+
+// spreadsheetIDFromPath gets the ID from a path like /v4/spreadsheets/{id}/values/{range}
+func spreadsheetIDFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "spreadsheets" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	numRequests := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: NewRateLimit(RateLimitConfig{PerMinute: 1, Burst: 2}, nil)}
+
+	for range 2 {
+		resp, err := client.Get(upstream.URL + "/v4/spreadsheets/ID/values/A1")
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	_, err := client.Get(upstream.URL + "/v4/spreadsheets/ID/values/A1")
+	assert.ErrorIs(t, err, ErrQuotaExhausted)
+	assert.Equal(t, 2, numRequests)
+}
+
+func TestRateLimitWait(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: NewRateLimit(RateLimitConfig{
+		PerMinute: 600,
+		Burst:     1,
+		MaxWait:   time.Second,
+	}, nil)}
+
+	start := time.Now()
+	for range 2 {
+		resp, err := client.Get(upstream.URL)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestSpreadsheetIDFromPath(t *testing.T) {
+	assert.Equal(t, "ID", spreadsheetIDFromPath("/v4/spreadsheets/ID/values/A1"))
+	assert.Equal(t, "ID", spreadsheetIDFromPath("/v4/spreadsheets/ID"))
+	assert.Equal(t, "unknown", spreadsheetIDFromPath("/other"))
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type upstreamKey struct{}
+
+// upstreamTracker records responses for requests made with a context from TrackUpstream
+type upstreamTracker struct {
+	mu        sync.Mutex
+	upstream  bool
+	fetchedAt time.Time
+}
+
+// TrackUpstream returns a context that records if any request made with it got a response from upstream
+// instead of the cache. Use FromUpstream and FetchedAt with the returned context to check after making requests
+func TrackUpstream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, upstreamKey{}, &upstreamTracker{})
+}
+
+// FromUpstream checks if a request made with a context from TrackUpstream got a response from upstream
+func FromUpstream(ctx context.Context) bool {
+	tracker, ok := ctx.Value(upstreamKey{}).(*upstreamTracker)
+	if !ok {
+		return false
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.upstream
+}
+
+// FetchedAt is when the oldest response for a context from TrackUpstream was fetched from upstream. Cached
+// responses keep the Date from when they were fetched, so this is the age of the data even if every response
+// came from the cache. It returns false if there were no successful responses
+func FetchedAt(ctx context.Context) (time.Time, bool) {
+	tracker, ok := ctx.Value(upstreamKey{}).(*upstreamTracker)
+	if !ok {
+		return time.Time{}, false
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.fetchedAt, !tracker.fetchedAt.IsZero()
+}
+
+func trackResponse(ctx context.Context, resp *http.Response, cached bool) {
+	tracker, ok := ctx.Value(upstreamKey{}).(*upstreamTracker)
+	if !ok || resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	fetchedAt := time.Now()
+	if cached {
+		var err error
+		fetchedAt, err = http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			return
+		}
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if !cached {
+		tracker.upstream = true
+	}
+	if tracker.fetchedAt.IsZero() || fetchedAt.Before(tracker.fetchedAt) {
+		tracker.fetchedAt = fetchedAt
+	}
+}
@@ -0,0 +1,236 @@
+package azstocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Filter narrows a Calendar's weeks or excludes the Calendar. It returns false to exclude the Calendar.
+// Filters are applied in order, so each one only sees the weeks kept by the previous filters
+type Filter func(Calendar) (Calendar, bool)
+
+// Filter applies the filters to each Calendar and returns the Calendars that are kept. It does not
+// modify the original StockingData
+func (s StockingData) Filter(filters ...Filter) StockingData {
+	result := StockingData{}
+	for _, calendar := range s {
+		calendar.Data = slices.Clone(calendar.Data)
+
+		keep := true
+		for _, filter := range filters {
+			calendar, keep = filter(calendar)
+			if !keep {
+				break
+			}
+		}
+		if keep {
+			result = append(result, calendar)
+		}
+	}
+	return result
+}
+
+// Between keeps stocking weeks with a range that overlaps the from and to dates, including both. A zero
+// time is not used, so only setting from includes all weeks after it. Calendars without any stockings in
+// the range are excluded
+func Between(from, to time.Time) Filter {
+	from, to = dateOnly(from), dateOnly(to)
+	return weekFilter(func(w Week) bool {
+		if w.Stock == NoneFish {
+			return false
+		}
+		return (from.IsZero() || !w.End().Before(from)) && (to.IsZero() || !w.Time().After(to))
+	})
+}
+
+// NextDays keeps stocking weeks in the next number of days, including today. A week that started
+// before today is kept until it ends
+func NextDays(days int) Filter {
+	today := dateOnly(getNow())
+	return Between(today, today.AddDate(0, 0, days-1))
+}
+
+// Species keeps weeks that stock any of the fish. Calendars without any of the fish are excluded
+func Species(fish ...Fish) Filter {
+	return weekFilter(func(w Week) bool {
+		return slices.Contains(fish, w.Stock)
+	})
+}
+
+// HasUpcoming excludes Calendars without a future stocking
+func HasUpcoming() Filter {
+	return func(c Calendar) (Calendar, bool) {
+		return c, c.Next().Year != 0
+	}
+}
+
+// StockedSince excludes Calendars that were not stocked on or after the date
+func StockedSince(since time.Time) Filter {
+	since = dateOnly(since)
+	return func(c Calendar) (Calendar, bool) {
+		last := c.Last()
+		return c, last.Year != 0 && !last.Time().Before(since)
+	}
+}
+
+// weekFilter creates a Filter that keeps weeks matching keep and excludes Calendars without any
+func weekFilter(keep func(Week) bool) Filter {
+	return func(c Calendar) (Calendar, bool) {
+		c.Data = slices.DeleteFunc(c.Data, func(w Week) bool {
+			return !keep(w)
+		})
+		return c, len(c.Data) > 0
+	}
+}
+
+// Query gets stocking data from multiple Programs and filters it
+type Query struct {
+	// Programs to get data for. If it is empty, all Programs are used
+	Programs []Program
+	// Waters to get data for. If it is empty, all waters are used
+	Waters  []string
+	Filters []Filter
+}
+
+// Get runs the Query and returns the results for each Program. A Program without any of the Waters is
+// not an error since they might be in a different Program
+func (q Query) Get(ctx context.Context, srv *sheets.Service, opts ...Option) (map[Program]StockingData, error) {
+	programs := q.Programs
+	if len(programs) == 0 {
+		programs = Programs
+	}
+
+	result := map[Program]StockingData{}
+	for _, program := range programs {
+		data, err := GetContext(ctx, srv, program, q.Waters, opts...)
+		if errors.Is(err, ErrNoMatchingWaters) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s data: %w", program, err)
+		}
+		result[program] = data.Filter(q.Filters...)
+	}
+	return result, nil
+}
+
+// FilterOptions are the values used to create Filters, like command flags or URL query parameters. Empty
+// values are not used
+type FilterOptions struct {
+	// From and To are dates like 2024-10-21 to only keep stockings in the range
+	From string
+	To   string
+	// Days only keeps stockings in the next number of days
+	Days string
+	// Species are fish names like trout or catfish
+	Species []string
+	// Upcoming only keeps waters with an upcoming stocking
+	Upcoming bool
+	// Since is a date to only keep waters stocked since then
+	Since string
+}
+
+// ParseFilters validates the FilterOptions and creates the Filters in the order they are applied
+func ParseFilters(opts FilterOptions) ([]Filter, error) {
+	parseDate := func(name, date string) (time.Time, error) {
+		if date == "" {
+			return time.Time{}, nil
+		}
+		t, err := ParseDate(date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return t, nil
+	}
+
+	filters := []Filter{}
+
+	from, err := parseDate("from", opts.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate("to", opts.To)
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() || !to.IsZero() {
+		filters = append(filters, Between(from, to))
+	}
+
+	if opts.Days != "" {
+		days, err := strconv.Atoi(opts.Days)
+		if err != nil || days < 1 {
+			return nil, fmt.Errorf("invalid days: %q", opts.Days)
+		}
+		filters = append(filters, NextDays(days))
+	}
+
+	if len(opts.Species) > 0 {
+		fish := []Fish{}
+		for _, name := range opts.Species {
+			f, err := ParseSpecies(name)
+			if err != nil {
+				return nil, err
+			}
+			fish = append(fish, f)
+		}
+		filters = append(filters, Species(fish...))
+	}
+
+	if opts.Upcoming {
+		filters = append(filters, HasUpcoming())
+	}
+
+	since, err := parseDate("since", opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() {
+		filters = append(filters, StockedSince(since))
+	}
+
+	return filters, nil
+}
+
+// ParseSpecies parses the name of a fish like "trout" or "catfish"
+func ParseSpecies(name string) (Fish, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trout":
+		return Trout, nil
+	case "catfish":
+		return Catfish, nil
+	default:
+		return "", fmt.Errorf("unknown species %q", name)
+	}
+}
+
+// ParseDate parses a date like "2024-10-21" in Arizona time
+func ParseDate(date string) (time.Time, error) {
+	t, err := time.ParseInLocation(time.DateOnly, date, azTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing date: %w", err)
+	}
+	return t, nil
+}
+
+// dateOnly is midnight of the time's date in Arizona. A zero time stays zero
+func dateOnly(t time.Time) time.Time {
+	return dateIn(t, azTime)
+}
+
+// dateIn is the time's date in the Location. It is midnight in Arizona so it can be compared with the
+// Weeks. A zero time stays zero
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, azTime)
+}
@@ -0,0 +1,149 @@
+package azstocker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	getNow = func() time.Time {
+		return time.Date(2024, time.October, 16, 12, 0, 0, 0, azTime)
+	}
+	defer func() { getNow = time.Now }()
+
+	data := StockingData{
+		{
+			WaterName: "Lake A",
+			Data: []Week{
+				{Year: 2024, Month: time.October, Day: 7, Stock: Catfish},
+				{Year: 2024, Month: time.October, Day: 14, Stock: NoneFish},
+				{Year: 2024, Month: time.October, Day: 21, Stock: Trout},
+				{Year: 2024, Month: time.October, Day: 28, Stock: Catfish},
+			},
+		},
+		{
+			WaterName: "Lake B",
+			Data: []Week{
+				{Year: 2024, Month: time.September, Day: 30, Stock: Trout},
+				{Year: 2024, Month: time.October, Day: 7, Stock: NoneFish},
+				{Year: 2024, Month: time.October, Day: 21, Stock: Catfish},
+			},
+		},
+		{
+			WaterName: "Lake C",
+			Data: []Week{
+				{Year: 2024, Month: time.October, Day: 14, Stock: Trout},
+				{Year: 2024, Month: time.October, Day: 21, Stock: NoneFish},
+			},
+		},
+	}
+
+	waterNames := func(data StockingData) []string {
+		result := []string{}
+		for _, c := range data {
+			result = append(result, c.WaterName)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		filters  []Filter
+		expected []string
+	}{
+		{"NoFilters", nil, []string{"Lake A", "Lake B", "Lake C"}},
+		{"Species", []Filter{Species(Catfish)}, []string{"Lake A", "Lake B"}},
+		{"Between", []Filter{Between(time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), time.Date(2024, time.October, 20, 0, 0, 0, 0, azTime))}, []string{"Lake C"}},
+		// weeks that started before the 22nd are kept until they end
+		{"BetweenOpenEnded", []Filter{Between(time.Date(2024, time.October, 22, 0, 0, 0, 0, azTime), time.Time{})}, []string{"Lake A", "Lake B"}},
+		{"BetweenAfterWeekEnds", []Filter{Between(time.Date(2024, time.October, 28, 0, 0, 0, 0, azTime), time.Time{})}, []string{"Lake A"}},
+		// Lake C's week started on the 14th and includes today
+		{"NextDays", []Filter{NextDays(7)}, []string{"Lake A", "Lake B", "Lake C"}},
+		{"CatfishThisWeek", []Filter{Species(Catfish), NextDays(7)}, []string{"Lake B"}},
+		// Lake C's week includes today, so it is still upcoming instead of stocked
+		{"HasUpcoming", []Filter{HasUpcoming()}, []string{"Lake A", "Lake B", "Lake C"}},
+		{"UpcomingTrout", []Filter{Species(Trout), HasUpcoming()}, []string{"Lake A", "Lake C"}},
+		{"StockedSince", []Filter{StockedSince(time.Date(2024, time.October, 7, 0, 0, 0, 0, azTime))}, []string{"Lake A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, waterNames(data.Filter(tt.filters...)))
+		})
+	}
+
+	t.Run("NarrowsWeeks", func(t *testing.T) {
+		result := data.Filter(Species(Catfish), NextDays(7))
+		assert.Equal(t, []Week{{Year: 2024, Month: time.October, Day: 21, Stock: Catfish}}, result[0].Data)
+		assert.Len(t, data[1].Data, 3, "original data should not be modified")
+	})
+}
+
+func TestQueryGet(t *testing.T) {
+	srv := newTestSheetsService(t, fakeWinterSheet([]any{"OCTOBER"}, []any{"LOWER SALT RIVER", "X"}))
+
+	result, err := Query{
+		Programs: []Program{WinterProgram},
+		Waters:   []string{"lower salt river"},
+		Filters:  []Filter{Species(Trout)},
+	}.Get(context.Background(), srv)
+	assert.NoError(t, err)
+	assert.Len(t, result[WinterProgram], 1)
+
+	t.Run("NoMatchingWaters", func(t *testing.T) {
+		result, err := Query{Programs: []Program{WinterProgram}, Waters: []string{"Lynx Lake"}}.Get(context.Background(), srv)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		_, err := Query{Programs: []Program{WinterProgram}}.Get(context.Background(), srv)
+		assert.ErrorIs(t, err, ErrUpstream)
+	})
+}
+
+func TestParseSpecies(t *testing.T) {
+	fish, err := ParseSpecies(" Trout")
+	assert.NoError(t, err)
+	assert.Equal(t, Trout, fish)
+
+	_, err = ParseSpecies("bass")
+	assert.EqualError(t, err, `unknown species "bass"`)
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        FilterOptions
+		numFilters  int
+		expectedErr string
+	}{
+		{"None", FilterOptions{}, 0, ""},
+		{"All", FilterOptions{From: "2024-10-01", To: "2024-10-31", Days: "7", Species: []string{"trout"}, Upcoming: true, Since: "2024-09-01"}, 5, ""},
+		{"OnlyTo", FilterOptions{To: "2024-10-31"}, 1, ""},
+		{"InvalidFrom", FilterOptions{From: "October"}, 0, `invalid from: error parsing date: parsing time "October" as "2006-01-02": cannot parse "October" as "2006"`},
+		{"InvalidSince", FilterOptions{Since: "10/01/2024"}, 0, `invalid since: error parsing date: parsing time "10/01/2024" as "2006-01-02": cannot parse "10/01/2024" as "2006"`},
+		{"InvalidDays", FilterOptions{Days: "week"}, 0, `invalid days: "week"`},
+		{"ZeroDays", FilterOptions{Days: "0"}, 0, `invalid days: "0"`},
+		{"InvalidSpecies", FilterOptions{Species: []string{"trout", "bass"}}, 0, `unknown species "bass"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := ParseFilters(tt.opts)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, filters, tt.numFilters)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package azstocker
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Status shows if a scheduled stocking actually happened, based on the stocking reports
+type Status string
+
+const (
+	// Completed stockings are in a report
+	Completed Status = "completed"
+	// Cancelled stockings are marked as cancelled in a report
+	Cancelled Status = "cancelled"
+	// Unconfirmed stockings have started, but are not in any report. A report might leave out a water or
+	// use a different name for it, so this does not mean the stocking was cancelled
+	Unconfirmed Status = "unconfirmed"
+)
+
+// reportClient is used to get reports from a URL. The timeout keeps a slow server from holding up a refresh
+var reportClient = &http.Client{Timeout: 30 * time.Second}
+
+// reportDateFormats are the date formats that are accepted in a report. Spreadsheet programs format dates
+// differently when a report is downloaded, so each of these is tried
+var reportDateFormats = []string{
+	time.DateOnly, "1/2/2006", "01/02/2006", "1/2/06", "1-2-2006", "1-2-06", "January 2, 2006", "Jan 2, 2006",
+}
+
+// reportColumns are the header names for each column of a report. Any of the names can be used since the
+// weekly reports don't always name the columns the same way
+var reportColumns = map[string][]string{
+	"water":   {"water", "water name", "location"},
+	"date":    {"date", "date stocked", "stocking date", "week of"},
+	"species": {"species", "species stocked", "fish"},
+	"status":  {"status", "notes", "comments"},
+}
+
+var (
+	errNoReportHeader    = errors.New("report does not have a header row with Water and Date columns")
+	errNoReportStockings = errors.New("report does not have any stockings")
+)
+
+// Report is a list of stockings that actually happened from AZGFD's weekly stocking reports
+type Report struct {
+	Stockings []ReportedStocking
+}
+
+// ReportedStocking is a single row in a Report
+type ReportedStocking struct {
+	Date      time.Time
+	WaterName string
+	Stock     Fish
+	Cancelled bool
+}
+
+// LoadReport reads a Report from a file or an http or https URL. A source ending in .xlsx is parsed with
+// ParseReportXLSX and others are parsed with ParseReport
+func LoadReport(ctx context.Context, source string) (Report, error) {
+	body, name, err := openReport(ctx, source)
+	if err != nil {
+		return Report{}, err
+	}
+	defer body.Close()
+
+	if strings.EqualFold(path.Ext(name), ".xlsx") {
+		return ParseReportXLSX(body)
+	}
+	return ParseReport(body)
+}
+
+// openReport opens the report file or gets it from the URL. It also returns the file name or URL path,
+// which has the file type
+func openReport(ctx context.Context, source string) (io.ReadCloser, string, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, "", fmt.Errorf("error opening report: %w", err)
+		}
+		return f, source, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := reportClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting report: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("error getting report: unexpected status %d", resp.StatusCode)
+	}
+	return resp.Body, req.URL.Path, nil
+}
+
+// ParseReport parses a weekly stocking report downloaded as CSV. Title rows before the header and region
+// headings between the stockings are skipped. The header is the first row with water and date columns,
+// which can be named like "Water" or "Water Name" and "Date", "Date Stocked", or "Week Of". Species and
+// status columns are optional, and a status or note that mentions "cancel" marks a scheduled stocking
+// that did not happen. See internal/testdata/stocking_report.csv for an example
+func ParseReport(r io.Reader) (Report, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading report: %w", err)
+	}
+
+	report, err := parseReportRows(rows)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(report.Stockings) == 0 {
+		return Report{}, errNoReportStockings
+	}
+	return report, nil
+}
+
+// ParseReportXLSX parses a weekly stocking report downloaded as XLSX. Each visible sheet with a header row
+// is parsed like ParseReport, so a report with a sheet for each region is combined
+func ParseReportXLSX(r io.Reader) (Report, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading report: %w", err)
+	}
+	defer f.Close()
+
+	report := Report{}
+	for _, name := range f.GetSheetList() {
+		visible, err := f.GetSheetVisible(name)
+		if err != nil {
+			return Report{}, fmt.Errorf("error reading sheet %q: %w", name, err)
+		}
+		if !visible {
+			continue
+		}
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return Report{}, fmt.Errorf("error reading sheet %q: %w", name, err)
+		}
+
+		sheetReport, err := parseReportRows(rows)
+		if errors.Is(err, errNoReportHeader) {
+			continue
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("error parsing sheet %q: %w", name, err)
+		}
+		report.Stockings = append(report.Stockings, sheetReport.Stockings...)
+	}
+	if len(report.Stockings) == 0 {
+		return Report{}, errNoReportStockings
+	}
+	return report, nil
+}
+
+// parseReportRows finds the header and parses each stocking after it
+func parseReportRows(rows [][]string) (Report, error) {
+	header := -1
+	var columns map[string]int
+	for i, row := range rows {
+		columns = reportHeader(row)
+		_, hasWater := columns["water"]
+		_, hasDate := columns["date"]
+		if hasWater && hasDate {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return Report{}, errNoReportHeader
+	}
+
+	column := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	report := Report{}
+	for i, row := range rows[header+1:] {
+		water, date := column(row, "water"), column(row, "date")
+		// region headings and notes don't have both a water and a date
+		if water == "" || date == "" {
+			continue
+		}
+
+		t, err := parseReportDate(date)
+		if err != nil {
+			return Report{}, fmt.Errorf("error parsing date on line %d: %w", header+i+2, err)
+		}
+
+		report.Stockings = append(report.Stockings, ReportedStocking{
+			Date:      t,
+			WaterName: water,
+			Stock:     parseReportFish(column(row, "species")),
+			Cancelled: strings.Contains(strings.ToLower(column(row, "status")), "cancel"),
+		})
+	}
+	return report, nil
+}
+
+// reportHeader maps each column in reportColumns to its index in the row. Names are compared without case
+// and extra spaces
+func reportHeader(row []string) map[string]int {
+	result := map[string]int{}
+	for i, cell := range row {
+		name := strings.ToLower(strings.Join(strings.Fields(cell), " "))
+		for column, names := range reportColumns {
+			if _, exists := result[column]; !exists && slices.Contains(names, name) {
+				result[column] = i
+			}
+		}
+	}
+	return result
+}
+
+// parseReportFish reads species names like "Rainbow Trout" or "Channel Catfish" from a report. A report
+// row is a stocking, so a missing species is UnknownFish instead of NoneFish
+func parseReportFish(species string) Fish {
+	species = strings.ToLower(species)
+	switch {
+	case strings.Contains(species, "catfish"):
+		return Catfish
+	case strings.Contains(species, "trout"):
+		return Trout
+	default:
+		return UnknownFish
+	}
+}
+
+func parseReportDate(date string) (time.Time, error) {
+	// dates can be the start of the week, like "Week of 10/14/2024"
+	if len(date) > len("week of") && strings.EqualFold(date[:len("week of")], "week of") {
+		date = strings.TrimSpace(date[len("week of"):])
+	}
+	for _, format := range reportDateFormats {
+		t, err := time.ParseInLocation(format, date, azTime)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format: %q", date)
+}
+
+// Reconcile sets the Status of each stocked Week that has started using the reports. Weeks in the future
+// do not have a Status. It does not modify the original StockingData
+func (s StockingData) Reconcile(reports ...Report) StockingData {
+	result := StockingData{}
+	for _, calendar := range s {
+		calendar.Data = slices.Clone(calendar.Data)
+		today := calendar.today()
+		for i, week := range calendar.Data {
+			if week.Stock == NoneFish || week.Time().After(today) {
+				continue
+			}
+			calendar.Data[i].Status = reconcileWeek(calendar.WaterName, week, reports)
+		}
+		result = append(result, calendar)
+	}
+	return result
+}
+
+// reconcileWeek finds the Week's Status from the reported stockings of the same water and species in the
+// Week's range. A report without a species matches any species. Only an explicit cancellation in a report
+// is Cancelled, and a water that is missing from the reports is Unconfirmed
+func reconcileWeek(water string, week Week, reports []Report) Status {
+	status := Unconfirmed
+	for _, report := range reports {
+		for _, stocking := range report.Stockings {
+			if stocking.Date.Before(week.Time()) || stocking.Date.After(week.End()) || !sameWater(water, stocking.WaterName) {
+				continue
+			}
+			if stocking.Stock != week.Stock && stocking.Stock != UnknownFish && week.Stock != UnknownFish {
+				continue
+			}
+			// another row for the same stocking can show it happened after a cancellation was reported
+			if !stocking.Cancelled {
+				return Completed
+			}
+			status = Cancelled
+		}
+	}
+	return status
+}
+
+// sameWater compares water names from the schedule and a report. Reports might not include the city
+// prefix, like "Kiwanis Lake" for "Tempe - Kiwanis Lake"
+func sameWater(scheduled, reported string) bool {
+	scheduled, reported = normalizeWater(scheduled), normalizeWater(reported)
+	if scheduled == reported {
+		return true
+	}
+	_, name, ok := strings.Cut(scheduled, " - ")
+	return ok && name == reported
+}
+
+// normalizeWater lowercases the name and removes punctuation other than the " - " separator
+func normalizeWater(name string) string {
+	city, water, ok := strings.Cut(name, " - ")
+	clean := func(s string) string {
+		return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}), " ")
+	}
+	if !ok {
+		return clean(name)
+	}
+	return clean(city) + " - " + clean(water)
+}
@@ -0,0 +1,244 @@
+package azstocker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+const testReport = `Date,Water,Species,Status
+10/14/2024,Kiwanis Lake,Rainbow Trout,
+2024-10-16,Tempe - Papago Ponds,Trout,Cancelled
+10/18/24,Green Valley Lakes,Catfish,
+`
+
+func TestParseReport(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		report, err := ParseReport(strings.NewReader(testReport))
+		require.NoError(t, err)
+
+		assert.Equal(t, Report{
+			Stockings: []ReportedStocking{
+				{Date: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), WaterName: "Kiwanis Lake", Stock: Trout},
+				{Date: time.Date(2024, time.October, 16, 0, 0, 0, 0, azTime), WaterName: "Tempe - Papago Ponds", Stock: Trout, Cancelled: true},
+				{Date: time.Date(2024, time.October, 18, 0, 0, 0, 0, azTime), WaterName: "Green Valley Lakes", Stock: Catfish},
+			},
+		}, report)
+	})
+
+	t.Run("OptionalColumns", func(t *testing.T) {
+		report, err := ParseReport(strings.NewReader("water,date\nKiwanis Lake,10/14/2024\n,10/15/2024\n"))
+		require.NoError(t, err)
+		assert.Len(t, report.Stockings, 1)
+		assert.Equal(t, UnknownFish, report.Stockings[0].Stock)
+		assert.False(t, report.Stockings[0].Cancelled)
+	})
+
+	t.Run("WeeklyReportLayout", func(t *testing.T) {
+		report, err := ParseReport(strings.NewReader(`Weekly Fish Stocking Report,,,
+"Week of October 14, 2024",,,
+Region,Water Name,Species Stocked,Date Stocked,Notes
+Phoenix Area,,,,
+,Kiwanis Lake,Rainbow Trout,"October 14, 2024",
+,Papago Ponds,Channel Catfish,Week of 10/14/2024,Canceled
+`))
+		require.NoError(t, err)
+		assert.Equal(t, Report{
+			Stockings: []ReportedStocking{
+				{Date: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), WaterName: "Kiwanis Lake", Stock: Trout},
+				{Date: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), WaterName: "Papago Ponds", Stock: Catfish, Cancelled: true},
+			},
+		}, report)
+	})
+
+	errorTests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"Empty", "", "report does not have a header row with Water and Date columns"},
+		{"MissingDate", "Water\nKiwanis Lake\n", "report does not have a header row with Water and Date columns"},
+		{"MissingWater", "Date\n10/14/2024\n", "report does not have a header row with Water and Date columns"},
+		{"InvalidDate", "Date,Water\nOctober 14,Kiwanis Lake\n", "error parsing date on line 2: unknown date format: \"October 14\""},
+		{"NoStockings", "Date,Water\n", "report does not have any stockings"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReport(strings.NewReader(tt.input))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.csv")
+		require.NoError(t, os.WriteFile(path, []byte(testReport), 0o600))
+
+		report, err := LoadReport(context.Background(), path)
+		require.NoError(t, err)
+		assert.Len(t, report.Stockings, 3)
+	})
+
+	// the example in testdata documents the layout in the README
+	t.Run("Example", func(t *testing.T) {
+		report, err := LoadReport(context.Background(), "internal/testdata/stocking_report.csv")
+		require.NoError(t, err)
+		assert.Len(t, report.Stockings, 4)
+		assert.Equal(t, Catfish, report.Stockings[1].Stock)
+		assert.True(t, report.Stockings[1].Cancelled)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.xlsx")
+		f := excelize.NewFile()
+		defer f.Close()
+
+		rows := map[string][][]any{
+			"Phoenix": {{"Weekly Fish Stocking Report"}, {"Water Name", "Species", "Date Stocked"}, {"Kiwanis Lake", "Rainbow Trout", "10/14/2024"}},
+			"Tucson":  {{"Water Name", "Species", "Date Stocked"}, {"Green Valley Lakes", "Channel Catfish", "10/18/2024"}},
+			"Notes":   {{"Stockings are subject to change"}},
+		}
+		require.NoError(t, f.SetSheetName("Sheet1", "Phoenix"))
+		for _, name := range []string{"Phoenix", "Tucson", "Notes"} {
+			_, err := f.NewSheet(name)
+			require.NoError(t, err)
+			for i, row := range rows[name] {
+				cell, err := excelize.CoordinatesToCellName(1, i+1)
+				require.NoError(t, err)
+				require.NoError(t, f.SetSheetRow(name, cell, &row))
+			}
+		}
+		require.NoError(t, f.SaveAs(path))
+
+		report, err := LoadReport(context.Background(), path)
+		require.NoError(t, err)
+		assert.Equal(t, []ReportedStocking{
+			{Date: time.Date(2024, time.October, 14, 0, 0, 0, 0, azTime), WaterName: "Kiwanis Lake", Stock: Trout},
+			{Date: time.Date(2024, time.October, 18, 0, 0, 0, 0, azTime), WaterName: "Green Valley Lakes", Stock: Catfish},
+		}, report.Stockings)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := LoadReport(context.Background(), filepath.Join(t.TempDir(), "report.csv"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/report.csv" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(testReport))
+		}))
+		defer srv.Close()
+
+		report, err := LoadReport(context.Background(), srv.URL+"/report.csv")
+		require.NoError(t, err)
+		assert.Len(t, report.Stockings, 3)
+
+		_, err = LoadReport(context.Background(), srv.URL+"/missing.csv")
+		assert.EqualError(t, err, "error getting report: unexpected status 404")
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	getNow = func() time.Time { return time.Date(2024, time.October, 23, 12, 0, 0, 0, azTime) }
+	defer func() { getNow = time.Now }()
+
+	report, err := ParseReport(strings.NewReader(testReport))
+	require.NoError(t, err)
+
+	week := func(day int, stock Fish) Week {
+		return Week{Year: 2024, Month: time.October, Day: day, EndMonth: time.October, EndDay: day + 4, Stock: stock}
+	}
+	data := StockingData{
+		{WaterName: "Tempe - Kiwanis Lake", Data: []Week{week(7, Trout), week(14, Trout), week(21, Trout), week(28, Trout)}},
+		{WaterName: "Tempe - Papago Ponds", Data: []Week{week(14, Trout)}},
+		{WaterName: "Green Valley Lakes", Data: []Week{week(14, Catfish), week(21, NoneFish)}},
+	}
+
+	result := data.Reconcile(report)
+
+	statuses := func(calendar Calendar) []Status {
+		result := []Status{}
+		for _, week := range calendar.Data {
+			result = append(result, week.Status)
+		}
+		return result
+	}
+	// the weeks of October 7 and 21 are not in the report, and the week of October 28 has not started
+	assert.Equal(t, []Status{Unconfirmed, Completed, Unconfirmed, ""}, statuses(result[0]))
+	assert.Equal(t, []Status{Cancelled}, statuses(result[1]))
+	assert.Equal(t, []Status{Completed, ""}, statuses(result[2]))
+
+	// waters that are missing from a report or have a different name in it are never Cancelled
+	t.Run("MissingFromReport", func(t *testing.T) {
+		result := StockingData{
+			{WaterName: "Lynx Lake", Data: []Week{week(14, Trout)}},
+			{WaterName: "Green Valley Lake", Data: []Week{week(14, Trout)}},
+		}.Reconcile(report)
+		assert.Equal(t, Unconfirmed, result[0].Data[0].Status)
+		assert.Equal(t, Unconfirmed, result[1].Data[0].Status)
+	})
+
+	// each species is reconciled separately when a water is stocked with both in the same week
+	t.Run("Species", func(t *testing.T) {
+		report, err := ParseReport(strings.NewReader(`Date,Water,Species,Status
+10/14/2024,Kiwanis Lake,Channel Catfish,Cancelled
+10/15/2024,Kiwanis Lake,Rainbow Trout,
+`))
+		require.NoError(t, err)
+
+		result := StockingData{
+			{WaterName: "Tempe - Kiwanis Lake", Data: []Week{week(14, Trout), week(14, Catfish)}},
+			{WaterName: "Tempe - Kiwanis Lake", Data: []Week{week(14, Catfish), week(14, Trout)}},
+		}.Reconcile(report)
+		assert.Equal(t, []Status{Completed, Cancelled}, statuses(result[0]))
+		assert.Equal(t, []Status{Cancelled, Completed}, statuses(result[1]))
+	})
+
+	t.Run("OriginalUnchanged", func(t *testing.T) {
+		for _, calendar := range data {
+			for _, week := range calendar.Data {
+				assert.Empty(t, week.Status)
+			}
+		}
+	})
+
+	t.Run("StringIn", func(t *testing.T) {
+		assert.Equal(t, "2024 October 14-18: \"Trout\" (completed)", result[0].Data[1].String())
+	})
+}
+
+func TestSameWater(t *testing.T) {
+	tests := []struct {
+		scheduled string
+		reported  string
+		expected  bool
+	}{
+		{"Tempe - Kiwanis Lake", "Tempe - Kiwanis Lake", true},
+		{"Tempe - Kiwanis Lake", "kiwanis lake", true},
+		{"Tempe - Kiwanis Lake", "TEMPE-KIWANIS LAKE", false},
+		{"Goldwater Lake (Lower)", "Goldwater Lake Lower", true},
+		{"Tempe - Kiwanis Lake", "Tempe", false},
+		{"Green Valley Lakes", "Green Valley Lake", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheduled+"/"+tt.reported, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sameWater(tt.scheduled, tt.reported))
+		})
+	}
+}
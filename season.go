@@ -0,0 +1,271 @@
+package azstocker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/sheets/v4"
+)
+
+// seasonPattern matches a sheet name and returns the Season's date range using the submatches
+type seasonPattern struct {
+	re    *regexp.Regexp
+	dates func(match []string) (start, end time.Time, ok bool)
+}
+
+// seasonPatterns are the sheet names for each Program. The first pattern is the usual name and the others
+// allow for small changes in the name. When multiple sheets are found for the same Season, the one that
+// matches the earliest pattern is used
+var seasonPatterns = map[Program][]seasonPattern{
+	CFProgram: {
+		{regexp.MustCompile(`^CFP Stocking Calendar Schedule$`), noDates},
+		{regexp.MustCompile(`(?i)\bCFP\b.*\bschedule\b`), noDates},
+	},
+	WinterProgram: {
+		{regexp.MustCompile(`^(\d{4})-(\d{4}|\d{2}) Winter$`), winterDates},
+		{regexp.MustCompile(`^(\d{4})-(\d{4}|\d{2})$`), winterDates},
+		{regexp.MustCompile(`(?i)(\d{4})\s*[-/]\s*(\d{4}|\d{2})\s+winter\b`), winterDates},
+		{regexp.MustCompile(`(?i)winter\s+(\d{4})\s*[-/]\s*(\d{4}|\d{2})\b`), winterDates},
+	},
+	SpringSummerProgram: {
+		{regexp.MustCompile(`^(\d{4}) Spring/Summer$`), springSummerDates},
+		{regexp.MustCompile(`(?i)(\d{4})\s+spring\s*[/&-]?\s*summer`), springSummerDates},
+		{regexp.MustCompile(`(?i)spring\s*[/&-]?\s*summer\s+(\d{4})`), springSummerDates},
+	},
+}
+
+func noDates([]string) (time.Time, time.Time, bool) {
+	return time.Time{}, time.Time{}, true
+}
+
+// winterDates are October of the first year through March of the second year
+func winterDates(match []string) (time.Time, time.Time, bool) {
+	startYear, _ := strconv.Atoi(match[1])
+	endYear, _ := strconv.Atoi(match[2])
+	// short end years like "25" only have the last two digits
+	if len(match[2]) == 2 && endYear == (startYear+1)%100 {
+		endYear = startYear + 1
+	}
+	if endYear != startYear+1 {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.Date(startYear, time.October, 1, 0, 0, 0, 0, azTime),
+		time.Date(endYear, time.March, 31, 0, 0, 0, 0, azTime),
+		true
+}
+
+// springSummerDates are April through September
+func springSummerDates(match []string) (time.Time, time.Time, bool) {
+	year, _ := strconv.Atoi(match[1])
+	return time.Date(year, time.April, 1, 0, 0, 0, 0, azTime),
+		time.Date(year, time.September, 30, 0, 0, 0, 0, azTime),
+		true
+}
+
+// Season is a single stocking season for a Program, which is a tab in the Program's spreadsheet. Winter
+// seasons run from October through March and Spring/Summer seasons run from April through September. The
+// CFP schedule is a single tab that is updated throughout the year, so its Start and End are not set
+type Season struct {
+	Program Program
+	// Name is the title of the sheet tab
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// String returns the Season's Name
+func (s Season) String() string {
+	return s.Name
+}
+
+// Contains checks if the date is within the Season. It is always true if the Season has no date range
+func (s Season) Contains(t time.Time) bool {
+	if s.Start.IsZero() || s.End.IsZero() {
+		return true
+	}
+	date := dateOnly(t)
+	return !date.Before(s.Start) && !date.After(s.End)
+}
+
+// yearFor chooses the year for a month in the Season's schedule. Each month gets the year that puts it
+// closest to the middle of the Season, so October uses the start year and March uses the end year for
+// a Winter season. Seasons without a date range use the current time
+func (s Season) yearFor(month time.Month) int {
+	middle := Now()
+	if !s.Start.IsZero() && !s.End.IsZero() {
+		middle = s.Start.Add(s.End.Sub(s.Start) / 2)
+	}
+
+	best := middle.Year()
+	bestDistance := time.Duration(-1)
+	for _, year := range []int{middle.Year() - 1, middle.Year(), middle.Year() + 1} {
+		distance := time.Date(year, month, 15, 0, 0, 0, 0, azTime).Sub(middle).Abs()
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = year, distance
+		}
+	}
+	return best
+}
+
+// ParseSeason creates a Season from a sheet name like "2024-25 Winter" or "2025 Spring/Summer"
+func ParseSeason(program Program, name string) (Season, error) {
+	season, _, ok := parseSeason(program, name)
+	if !ok {
+		return Season{}, fmt.Errorf("%q is not a season for program %q", name, program)
+	}
+	return season, nil
+}
+
+// parseSeason creates a Season from a sheet name. It also returns the index of the matching pattern, which
+// is lower for better matches. It returns false if the name is not a season for the Program
+func parseSeason(program Program, name string) (Season, int, bool) {
+	name = strings.TrimSpace(name)
+	for i, pattern := range seasonPatterns[program] {
+		match := pattern.re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		start, end, ok := pattern.dates(match)
+		if !ok {
+			continue
+		}
+		return Season{Program: program, Name: name, Start: start, End: end}, i, true
+	}
+	return Season{}, 0, false
+}
+
+// Seasons lists the Program's seasons that are available in its spreadsheet, sorted by start date. It uses
+// the spreadsheet's metadata, so it does not read any schedule data. Options like WithSpreadsheet are used
+// to choose where the seasons are read from
+func Seasons(ctx context.Context, srv *sheets.Service, program Program, opts ...Option) ([]Season, error) {
+	ctx, span := tracer.Start(ctx, "Seasons", trace.WithAttributes(
+		attribute.String("program", string(program)),
+	))
+	defer span.End()
+
+	s := newSheet(srv, program)
+	if s == nil {
+		err := fmt.Errorf("%w: %q", ErrUnknownProgram, program)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	seasons, err := s.seasons(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// CurrentSeason chooses the Season to show on the provided date in Arizona. This is the Season that contains
+// the date, or the next Season if its schedule is already available. Otherwise, it is the most recent Season.
+// Use Now for the current Season
+func CurrentSeason(seasons []Season, now time.Time) (Season, bool) {
+	if len(seasons) == 0 {
+		return Season{}, false
+	}
+	now = now.In(azTime)
+
+	for _, season := range seasons {
+		if season.Contains(now) {
+			return season, true
+		}
+	}
+
+	for _, season := range seasons {
+		if season.Start.After(now) {
+			return season, true
+		}
+	}
+
+	return seasons[len(seasons)-1], true
+}
+
+// seasons reads the sheet names from the spreadsheet metadata. Hidden sheets are ignored. If multiple
+// sheets match the same Season, the best match is used
+func (s *sheet) seasons(ctx context.Context) ([]Season, error) {
+	ctx, span := tracer.Start(ctx, "getSheetNames", trace.WithAttributes(
+		attribute.String("spreadsheet_id", s.spreadsheetID),
+	))
+	defer span.End()
+
+	var sheetList []*sheets.Sheet
+	if s.spreadsheet != nil {
+		sheetList = s.spreadsheet.metadata()
+	} else {
+		resp, err := s.srv.Spreadsheets.Get(s.spreadsheetID).
+			Fields("sheets.properties(title,hidden)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			err = classifyError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("error getting spreadsheet metadata: %w", err)
+		}
+		sheetList = resp.Sheets
+	}
+
+	type rankedSeason struct {
+		Season
+		rank int
+	}
+	best := map[int64]rankedSeason{}
+	for _, sh := range sheetList {
+		if sh.Properties == nil || sh.Properties.Hidden {
+			continue
+		}
+		season, rank, ok := parseSeason(s.program, sh.Properties.Title)
+		if !ok {
+			continue
+		}
+		current, exists := best[season.Start.Unix()]
+		if !exists || rank < current.rank {
+			best[season.Start.Unix()] = rankedSeason{season, rank}
+		}
+	}
+
+	result := []Season{}
+	for _, season := range best {
+		result = append(result, season.Season)
+	}
+	slices.SortFunc(result, func(a, b Season) int {
+		return a.Start.Compare(b.Start)
+	})
+	span.SetAttributes(attribute.Int("seasons", len(result)))
+
+	return result, nil
+}
+
+// chooseSeason sets the sheet's Season to the current one if it was not set by an Option
+func (s *sheet) chooseSeason(ctx context.Context) error {
+	if s.season.Name != "" {
+		if s.season.Program != s.program {
+			return fmt.Errorf("season %q is not for program %q", s.season.Name, s.program)
+		}
+		return nil
+	}
+
+	seasons, err := s.seasons(ctx)
+	if err != nil {
+		return err
+	}
+
+	season, ok := CurrentSeason(seasons, Now())
+	if !ok {
+		return fmt.Errorf("%w: no seasons found for program %q", ErrSheetNotFound, s.program)
+	}
+	s.season = season
+	return nil
+}
@@ -0,0 +1,179 @@
+package azstocker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeason(t *testing.T) {
+	tests := []struct {
+		name          string
+		program       Program
+		sheetName     string
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedErr   string
+	}{
+		{
+			"WinterShortYear",
+			WinterProgram,
+			"2024-25 Winter",
+			time.Date(2024, time.October, 1, 0, 0, 0, 0, azTime),
+			time.Date(2025, time.March, 31, 0, 0, 0, 0, azTime),
+			"",
+		},
+		{
+			"WinterLongYear",
+			WinterProgram,
+			"2099-2100",
+			time.Date(2099, time.October, 1, 0, 0, 0, 0, azTime),
+			time.Date(2100, time.March, 31, 0, 0, 0, 0, azTime),
+			"",
+		},
+		{
+			"WinterCenturyRollover",
+			WinterProgram,
+			"2099-00 Winter",
+			time.Date(2099, time.October, 1, 0, 0, 0, 0, azTime),
+			time.Date(2100, time.March, 31, 0, 0, 0, 0, azTime),
+			"",
+		},
+		{
+			"SpringSummer",
+			SpringSummerProgram,
+			"2025 Spring/Summer",
+			time.Date(2025, time.April, 1, 0, 0, 0, 0, azTime),
+			time.Date(2025, time.September, 30, 0, 0, 0, 0, azTime),
+			"",
+		},
+		{
+			"CFP",
+			CFProgram,
+			"CFP Stocking Calendar Schedule",
+			time.Time{},
+			time.Time{},
+			"",
+		},
+		{
+			"WrongProgram",
+			SpringSummerProgram,
+			"2024-25 Winter",
+			time.Time{},
+			time.Time{},
+			`"2024-25 Winter" is not a season for program "springsummer"`,
+		},
+		{
+			"NotConsecutiveYears",
+			WinterProgram,
+			"2024-26 Winter",
+			time.Time{},
+			time.Time{},
+			`"2024-26 Winter" is not a season for program "winter"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season, err := ParseSeason(tt.program, tt.sheetName)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Season{Program: tt.program, Name: tt.sheetName, Start: tt.expectedStart, End: tt.expectedEnd}, season)
+		})
+	}
+}
+
+func TestCurrentSeason(t *testing.T) {
+	mustParse := func(name string) Season {
+		season, err := ParseSeason(WinterProgram, name)
+		assert.NoError(t, err)
+		return season
+	}
+	seasons := []Season{mustParse("2023-24 Winter"), mustParse("2024-25 Winter")}
+
+	tests := []struct {
+		name     string
+		seasons  []Season
+		now      time.Time
+		expected string
+	}{
+		{"StartOfSeason", seasons, time.Date(2024, time.October, 1, 8, 0, 0, 0, azTime), "2024-25 Winter"},
+		{"AfterNewYear", seasons, time.Date(2025, time.January, 10, 8, 0, 0, 0, azTime), "2024-25 Winter"},
+		{"LastDayOfSeason", seasons, time.Date(2024, time.March, 31, 23, 0, 0, 0, azTime), "2023-24 Winter"},
+		// 6AM UTC on April 1 is still March 31 in Arizona
+		{"LastDayOfSeasonUTC", seasons, time.Date(2024, time.April, 1, 6, 0, 0, 0, time.UTC), "2023-24 Winter"},
+		{"BetweenSeasonsUsesMostRecent", seasons, time.Date(2025, time.June, 1, 8, 0, 0, 0, azTime), "2024-25 Winter"},
+		{
+			"BetweenSeasonsUsesPublishedNextSeason",
+			append(seasons, mustParse("2025-26 Winter")),
+			time.Date(2025, time.September, 1, 8, 0, 0, 0, azTime),
+			"2025-26 Winter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season, ok := CurrentSeason(tt.seasons, tt.now)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, season.Name)
+		})
+	}
+
+	t.Run("NoSeasons", func(t *testing.T) {
+		_, ok := CurrentSeason(nil, time.Now())
+		assert.False(t, ok)
+	})
+}
+
+func TestSeasonYearFor(t *testing.T) {
+	winter, err := ParseSeason(WinterProgram, "2024-25 Winter")
+	assert.NoError(t, err)
+	assert.Equal(t, 2024, winter.yearFor(time.October))
+	assert.Equal(t, 2024, winter.yearFor(time.December))
+	assert.Equal(t, 2025, winter.yearFor(time.January))
+	assert.Equal(t, 2025, winter.yearFor(time.March))
+
+	springSummer, err := ParseSeason(SpringSummerProgram, "2025 Spring/Summer")
+	assert.NoError(t, err)
+	assert.Equal(t, 2025, springSummer.yearFor(time.March))
+	assert.Equal(t, 2025, springSummer.yearFor(time.October))
+
+	getNow = func() time.Time {
+		return time.Date(2025, time.January, 5, 0, 0, 0, 0, azTime)
+	}
+	defer func() { getNow = time.Now }()
+
+	cfp, err := ParseSeason(CFProgram, "CFP Stocking Calendar Schedule")
+	assert.NoError(t, err)
+	assert.Equal(t, 2024, cfp.yearFor(time.November))
+	assert.Equal(t, 2025, cfp.yearFor(time.February))
+}
+
+func TestSeasonsBestMatch(t *testing.T) {
+	srv := newTestSheetsService(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sheets.properties(title,hidden)", r.URL.Query().Get("fields"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sheets":[
+			{"properties":{"title":"Copy of 2024-25 Winter"}},
+			{"properties":{"title":"2024-25 Winter"}},
+			{"properties":{"title":"Winter 2023-2024"}},
+			{"properties":{"title":"2022-23 Winter","hidden":true}},
+			{"properties":{"title":"Notes"}}
+		]}`))
+	})
+
+	seasons, err := Seasons(context.Background(), srv, WinterProgram)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, season := range seasons {
+		names = append(names, season.Name)
+	}
+	assert.Equal(t, []string{"Winter 2023-2024", "2024-25 Winter"}, names)
+}
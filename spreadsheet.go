@@ -0,0 +1,250 @@
+package azstocker
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Spreadsheet is a downloaded copy of the stocking spreadsheets. It is used instead of Google Sheets with
+// WithSpreadsheet. Like the tabs in Google Sheets, its sheets are matched to a Program and Season by name
+type Spreadsheet struct {
+	sheets []fileSheet
+}
+
+type fileSheet struct {
+	name   string
+	hidden bool
+	rows   [][]string
+}
+
+// WithSpreadsheet reads the schedule from downloaded files instead of Google Sheets
+func WithSpreadsheet(spreadsheet *Spreadsheet) Option {
+	return func(s *sheet) {
+		s.spreadsheet = spreadsheet
+	}
+}
+
+// OpenSpreadsheet reads CSV and XLSX files. Each sheet in an XLSX file keeps its name. A CSV file is a single
+// sheet named after the file, like "2024-25 Winter.csv". Google Sheets names downloads like
+// "Spreadsheet - Sheet.csv", so only the part after the last " - " is used
+func OpenSpreadsheet(paths ...string) (*Spreadsheet, error) {
+	result := &Spreadsheet{}
+	for _, path := range paths {
+		var fileSheets []fileSheet
+		var err error
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			fileSheets, err = readCSVSheet(path)
+		case ".xlsx":
+			fileSheets, err = readXLSXSheets(path)
+		default:
+			return nil, fmt.Errorf("unsupported file type: %q", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %q: %w", path, err)
+		}
+
+		for _, sh := range fileSheets {
+			if _, exists := result.sheet(sh.name); exists {
+				return nil, fmt.Errorf("duplicate sheet %q in %q", sh.name, path)
+			}
+			result.sheets = append(result.sheets, sh)
+		}
+	}
+	return result, nil
+}
+
+// OpenSpreadsheetDir reads all CSV and XLSX files in the directory as a single Spreadsheet, so it can have
+// the schedules for every Program
+func OpenSpreadsheetDir(dir string) (*Spreadsheet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		// skip hidden files and lock files from Excel or LibreOffice
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".csv", ".xlsx":
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no CSV or XLSX files in %q", dir)
+	}
+
+	return OpenSpreadsheet(paths...)
+}
+
+func readCSVSheet(path string) ([]fileSheet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows := [][]string{}
+	// nextLine is the line after the previous record. Blank lines are skipped by the reader, but they are
+	// empty rows that need to be kept so ranges like "A9:AD" start on the right row
+	nextLine := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		for ; nextLine < line; nextLine++ {
+			rows = append(rows, []string{})
+		}
+		// quoted cells can have newlines, so the record might end on a later line
+		lastLine, _ := reader.FieldPos(len(record) - 1)
+		nextLine = lastLine + strings.Count(record[len(record)-1], "\n") + 1
+
+		rows = append(rows, record)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if i := strings.LastIndex(name, " - "); i >= 0 {
+		name = name[i+len(" - "):]
+	}
+	return []fileSheet{{name: strings.TrimSpace(name), rows: rows}}, nil
+}
+
+func readXLSXSheets(path string) ([]fileSheet, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := []fileSheet{}
+	for _, name := range f.GetSheetList() {
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sheet %q: %w", name, err)
+		}
+		visible, err := f.GetSheetVisible(name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sheet %q: %w", name, err)
+		}
+		result = append(result, fileSheet{name: name, hidden: !visible, rows: rows})
+	}
+	return result, nil
+}
+
+func (s *Spreadsheet) sheet(name string) (fileSheet, bool) {
+	for _, sh := range s.sheets {
+		if sh.name == name {
+			return sh, true
+		}
+	}
+	return fileSheet{}, false
+}
+
+// metadata describes the sheets the same way as the Sheets API so Seasons are found the same way
+func (s *Spreadsheet) metadata() []*sheets.Sheet {
+	result := []*sheets.Sheet{}
+	for _, sh := range s.sheets {
+		result = append(result, &sheets.Sheet{Properties: &sheets.SheetProperties{Title: sh.name, Hidden: sh.hidden}})
+	}
+	return result
+}
+
+// values gets a range of cells from a sheet. Like the Sheets API, empty cells at the end of a row and empty
+// rows at the end of the range are left out
+func (s *Spreadsheet) values(sheetName, targetRange string) ([][]any, error) {
+	sh, ok := s.sheet(sheetName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSheetNotFound, sheetName)
+	}
+
+	r, err := parseA1Range(targetRange)
+	if err != nil {
+		return nil, err
+	}
+
+	result := [][]any{}
+	for i := r.startRow; i < len(sh.rows) && (r.endRow < 0 || i <= r.endRow); i++ {
+		row := sh.rows[i]
+		cells := []any{}
+		for j := r.startCol; j < len(row) && (r.endCol < 0 || j <= r.endCol); j++ {
+			cells = append(cells, row[j])
+		}
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		result = append(result, cells)
+	}
+	for len(result) > 0 && len(result[len(result)-1]) == 0 {
+		result = result[:len(result)-1]
+	}
+	return result, nil
+}
+
+// a1Range is a range in A1 notation like "A9:AD" or "B4:5". Columns and rows start at zero, and an end of -1
+// continues to the end of the sheet
+type a1Range struct {
+	startCol, startRow int
+	endCol, endRow     int
+}
+
+func parseA1Range(r string) (a1Range, error) {
+	start, end, _ := strings.Cut(r, ":")
+
+	startCol, startRow, err := parseA1Cell(start)
+	if err != nil || startCol < 0 || startRow < 0 {
+		return a1Range{}, fmt.Errorf("invalid range %q", r)
+	}
+	endCol, endRow, err := parseA1Cell(end)
+	if err != nil {
+		return a1Range{}, fmt.Errorf("invalid range %q", r)
+	}
+
+	return a1Range{startCol, startRow, endCol, endRow}, nil
+}
+
+// parseA1Cell parses a cell like "B4" into zero-indexed column and row. A missing column or row is -1
+func parseA1Cell(cell string) (int, int, error) {
+	letters := strings.ToUpper(strings.TrimRight(cell, "0123456789"))
+	digits := cell[len(letters):]
+
+	col := -1
+	for _, c := range letters {
+		if c < 'A' || c > 'Z' {
+			return 0, 0, errors.New("invalid column")
+		}
+		col = (col+1)*26 + int(c-'A')
+	}
+
+	row := -1
+	if digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid row")
+		}
+		row = n - 1
+	}
+	return col, row, nil
+}
@@ -0,0 +1,202 @@
+package azstocker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// testScheduleRows is a small Winter schedule with the same layout as the spreadsheet
+var testScheduleRows = [][]string{
+	{"Winter Stocking Schedule"},
+	{},
+	{},
+	{"", "October", "", "", "November"},
+	{"", "7", "14", "21 - 25", "4"},
+	{},
+	{},
+	{"Water"},
+	{"Flagstaff Area"},
+	{"Lynx Lake", "X", "", "C"},
+	{"Goldwater Lake", "", "X", "", "X"},
+}
+
+var expectedFileData = StockingData{
+	{WaterName: "Lynx Lake", Region: "Flagstaff Area", Data: []Week{
+		{Year: 2024, Month: time.October, Day: 7, EndMonth: time.October, EndDay: 13, Stock: Trout},
+		{Year: 2024, Month: time.October, Day: 14, EndMonth: time.October, EndDay: 20, Stock: NoneFish},
+		{Year: 2024, Month: time.October, Day: 21, EndMonth: time.October, EndDay: 25, Stock: Catfish},
+		{Year: 2024, Month: time.November, Day: 4, EndMonth: time.November, EndDay: 10, Stock: NoneFish},
+	}},
+	{WaterName: "Goldwater Lake", Region: "Flagstaff Area", Data: []Week{
+		{Year: 2024, Month: time.October, Day: 7, EndMonth: time.October, EndDay: 13, Stock: NoneFish},
+		{Year: 2024, Month: time.October, Day: 14, EndMonth: time.October, EndDay: 20, Stock: Trout},
+		{Year: 2024, Month: time.October, Day: 21, EndMonth: time.October, EndDay: 25, Stock: NoneFish},
+		{Year: 2024, Month: time.November, Day: 4, EndMonth: time.November, EndDay: 10, Stock: Trout},
+	}},
+}
+
+func writeTestCSV(t *testing.T, path string) {
+	t.Helper()
+
+	lines := []string{}
+	for _, row := range testScheduleRows {
+		lines = append(lines, strings.Join(row, ","))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+}
+
+func writeTestXLSX(t *testing.T, path string) {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	require.NoError(t, f.SetSheetName("Sheet1", "2024-25 Winter"))
+	for i, row := range testScheduleRows {
+		cells := []any{}
+		for _, cell := range row {
+			cells = append(cells, cell)
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("2024-25 Winter", cell, &cells))
+	}
+
+	_, err := f.NewSheet("2023-24 Winter")
+	require.NoError(t, err)
+	require.NoError(t, f.SetSheetVisible("2023-24 Winter", false))
+	_, err = f.NewSheet("Notes")
+	require.NoError(t, err)
+
+	require.NoError(t, f.SaveAs(path))
+}
+
+func TestOpenSpreadsheet(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "Winter Stocking - 2024-25 Winter.csv")
+	xlsxPath := filepath.Join(dir, "winter.xlsx")
+	writeTestCSV(t, csvPath)
+	writeTestXLSX(t, xlsxPath)
+
+	for _, path := range []string{csvPath, xlsxPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			spreadsheet, err := OpenSpreadsheet(path)
+			require.NoError(t, err)
+
+			seasons, err := Seasons(context.Background(), nil, WinterProgram, WithSpreadsheet(spreadsheet))
+			require.NoError(t, err)
+			require.Len(t, seasons, 1)
+			assert.Equal(t, "2024-25 Winter", seasons[0].Name)
+
+			data, err := GetContext(context.Background(), nil, WinterProgram, nil, WithSpreadsheet(spreadsheet))
+			require.NoError(t, err)
+			assert.Equal(t, expectedFileData, data)
+
+			_, err = GetContext(context.Background(), nil, CFProgram, nil, WithSpreadsheet(spreadsheet))
+			assert.ErrorIs(t, err, ErrSheetNotFound)
+		})
+	}
+
+	t.Run("DuplicateSheet", func(t *testing.T) {
+		_, err := OpenSpreadsheet(csvPath, xlsxPath)
+		assert.EqualError(t, err, `duplicate sheet "2024-25 Winter" in "`+xlsxPath+`"`)
+	})
+
+	t.Run("UnsupportedFile", func(t *testing.T) {
+		_, err := OpenSpreadsheet(filepath.Join(dir, "schedule.ods"))
+		assert.EqualError(t, err, `unsupported file type: "`+filepath.Join(dir, "schedule.ods")+`"`)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := OpenSpreadsheet(filepath.Join(dir, "missing.csv"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestOpenSpreadsheetDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCSV(t, filepath.Join(dir, "2024-25 Winter.csv"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "~$winter.xlsx"), []byte("lock"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("notes"), 0o600))
+
+	spreadsheet, err := OpenSpreadsheetDir(dir)
+	require.NoError(t, err)
+
+	data, err := GetContext(context.Background(), nil, WinterProgram, []string{"Lynx Lake"}, WithSpreadsheet(spreadsheet))
+	require.NoError(t, err)
+	assert.Equal(t, expectedFileData[:1], data)
+
+	t.Run("Empty", func(t *testing.T) {
+		empty := t.TempDir()
+		_, err := OpenSpreadsheetDir(empty)
+		assert.EqualError(t, err, `no CSV or XLSX files in "`+empty+`"`)
+	})
+}
+
+func TestSpreadsheetValues(t *testing.T) {
+	spreadsheet := &Spreadsheet{sheets: []fileSheet{{name: "Sheet", rows: [][]string{
+		{"A1", "B1", "C1", ""},
+		{"A2", "", "", ""},
+		{},
+		{"A4", "B4", "C4", "D4"},
+		{"", "", ""},
+	}}}}
+
+	tests := []struct {
+		name     string
+		a1       string
+		expected [][]any
+	}{
+		{"OpenEnded", "B1:D", [][]any{{"B1", "C1"}, {}, {}, {"B4", "C4", "D4"}}},
+		{"Rows", "A1:2", [][]any{{"A1", "B1", "C1"}, {"A2"}}},
+		{"Bounded", "A2:B4", [][]any{{"A2"}, {}, {"A4", "B4"}}},
+		{"PastEnd", "A10:Z", [][]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := spreadsheet.values("Sheet", tt.a1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+
+	t.Run("MissingSheet", func(t *testing.T) {
+		_, err := spreadsheet.values("Other", "A1:B")
+		assert.ErrorIs(t, err, ErrSheetNotFound)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		_, err := spreadsheet.values("Sheet", "1:B")
+		assert.EqualError(t, err, `invalid range "1:B"`)
+	})
+}
+
+func TestParseA1Range(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected a1Range
+	}{
+		{"A11:Z", a1Range{0, 10, 25, -1}},
+		{"B8:9", a1Range{1, 7, -1, 8}},
+		{"A9:AD", a1Range{0, 8, 29, -1}},
+		{"B4:5", a1Range{1, 3, -1, 4}},
+		{"b2:c3", a1Range{1, 1, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := parseA1Range(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package azstocker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	// embed the time zone database since the alpine image does not include it
+	_ "time/tzdata"
+)
+
+var (
+	// Arizona is the time zone used for stocking dates. Most of the state does not observe daylight saving time
+	Arizona = mustLoadLocation("America/Phoenix")
+	// NavajoNation observes daylight saving time, unlike the rest of Arizona
+	NavajoNation = mustLoadLocation("America/Denver")
+
+	azTime = Arizona
+)
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("error loading time zone %q: %v", name, err))
+	}
+	return loc
+}
+
+// navajoNationWaters are waters on the Navajo Nation that are managed by the Navajo Nation Department of
+// Fish and Wildlife (https://www.nndfw.org), from its list of fishing lakes. They are matched by
+// their name in the schedule without case
+var navajoNationWaters = []string{
+	"cow springs lake",
+	"ganado lake",
+	"many farms lake",
+	"round rock lake",
+	"tsaile lake",
+	"wheatfields lake",
+}
+
+// Location is the time zone for the Calendar's water. Waters on the Navajo Nation use NavajoNation and
+// others use Arizona
+func (s Calendar) Location() *time.Location {
+	if slices.Contains(navajoNationWaters, strings.ToLower(strings.TrimSpace(s.WaterName))) {
+		return NavajoNation
+	}
+	return Arizona
+}
+
+// today is the current date at the Calendar's water. It is midnight in Arizona so it can be compared with
+// the Weeks, which only use dates
+func (s Calendar) today() time.Time {
+	return dateIn(getNow(), s.Location())
+}
+
+// Today returns the stocking Week that is scheduled for today at the Calendar's water. This is the date in
+// the schedule, which is the first day of the Week's range. It is an empty Week if there is not a stocking
+// today
+func (s Calendar) Today() Week {
+	today := s.today()
+	for _, data := range s.Data {
+		if data.Stock != NoneFish && data.Time().Equal(today) {
+			return data
+		}
+	}
+	return Week{}
+}
+
+// HumanTimeIn is Week.HumanTimeIn using the current date at the Calendar's water
+func (s Calendar) HumanTimeIn(lang Language, week Week) string {
+	return week.humanTimeIn(lang, s.Location())
+}
@@ -0,0 +1,77 @@
+package azstocker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar Calendar
+		expected *time.Location
+	}{
+		{"Phoenix", Calendar{WaterName: "Tempe - Kiwanis Lake"}, Arizona},
+		{"NavajoCounty", Calendar{WaterName: "Show Low Lake", Region: "Navajo County"}, Arizona},
+		{"NavajoNation", Calendar{WaterName: "WHEATFIELDS LAKE"}, NavajoNation},
+		{"NavajoNationSpaces", Calendar{WaterName: " Tsaile Lake "}, NavajoNation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.calendar.Location())
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	weeks := []Week{
+		{Year: 2024, Month: time.July, Day: 1, EndMonth: time.July, EndDay: 5, Stock: Catfish},
+		{Year: 2024, Month: time.July, Day: 8, EndMonth: time.July, EndDay: 12, Stock: Trout},
+	}
+	phoenix := Calendar{WaterName: "Tempe - Kiwanis Lake", Data: weeks}
+	navajo := Calendar{WaterName: "Tsaile Lake", Data: weeks}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		calendar Calendar
+		today    Week
+		human    string
+		next     Week
+		last     Week
+	}{
+		{"Morning", time.Date(2024, time.July, 8, 0, 30, 0, 0, Arizona), phoenix, weeks[1], "today", weeks[1], weeks[0]},
+		{"LaterInWeek", time.Date(2024, time.July, 9, 12, 0, 0, 0, Arizona), phoenix, Week{}, "this week", weeks[1], weeks[0]},
+		{"Night", time.Date(2024, time.July, 12, 23, 30, 0, 0, Arizona), phoenix, Week{}, "this week", weeks[1], weeks[0]},
+		{"NextDay", time.Date(2024, time.July, 13, 0, 30, 0, 0, Arizona), phoenix, Week{}, "", Week{}, weeks[1]},
+		{"Between", time.Date(2024, time.July, 6, 12, 0, 0, 0, Arizona), phoenix, Week{}, "1 day from now", weeks[1], weeks[0]},
+		// 11:30PM in Phoenix is already the next day on the Navajo Nation during daylight saving time
+		{"NavajoNation", time.Date(2024, time.July, 12, 23, 30, 0, 0, Arizona), navajo, Week{}, "", Week{}, weeks[1]},
+		{"NavajoNationBefore", time.Date(2024, time.July, 7, 23, 30, 0, 0, Arizona), navajo, weeks[1], "today", weeks[1], weeks[0]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getNow = func() time.Time { return tt.now }
+			defer func() { getNow = time.Now }()
+
+			assert.Equal(t, tt.today, tt.calendar.Today())
+			if tt.human != "" {
+				assert.Equal(t, tt.human, tt.calendar.HumanTimeIn(English, tt.next))
+			}
+			assert.Equal(t, tt.next, tt.calendar.Next())
+			assert.Equal(t, tt.last, tt.calendar.Last())
+		})
+	}
+
+	t.Run("DetailFormat", func(t *testing.T) {
+		getNow = func() time.Time { return time.Date(2024, time.July, 8, 12, 0, 0, 0, Arizona) }
+		defer func() { getNow = time.Now }()
+
+		assert.Equal(t, "Today: 2024 July 8-12: \"Trout\"\n", phoenix.DetailFormat(false, false, true, false))
+		assert.Equal(t, "Hoy: 2024 julio 8-12: \"Trucha\"\nÚltima: 2024 julio 1-5: \"Bagre\"\n", phoenix.DetailFormatIn(Spanish, false, false, false, true))
+	})
+}